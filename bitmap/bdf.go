@@ -0,0 +1,180 @@
+package bitmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Splits a BDF line into its keyword and arguments, keeping quoted
+// property values whole
+func bdfFields(line string) (string, []string) {
+	key, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`) && len(rest) >= 2 {
+		return key, []string{strings.ReplaceAll(rest[1:len(rest)-1], `""`, `"`)}
+	}
+	return key, strings.Fields(rest)
+}
+
+func bdfInts(args []string, n int) ([]int, error) {
+	if len(args) < n {
+		return nil, fmt.Errorf("bdf: expected %d numbers, got %q", n, strings.Join(args, " "))
+	}
+	ret := make([]int, n)
+	for idx := range n {
+		v, err := strconv.Atoi(args[idx])
+		if err != nil {
+			return nil, fmt.Errorf("bdf: %w", err)
+		}
+		ret[idx] = v
+	}
+	return ret, nil
+}
+
+// A bounding box as width, height, x and y. Bitmaps past a megabyte a glyph
+// are refused rather than allocated.
+func bdfBox(args []string) ([]int, error) {
+	box, err := bdfInts(args, 4)
+	if err != nil {
+		return nil, err
+	}
+	width, height := box[0], box[1]
+	if width < 0 || height < 0 {
+		return nil, fmt.Errorf("bdf: negative bounding box %dx%d", width, height)
+	}
+	if width > 1<<16 || height > 1<<16 || (width+7)/8*height > 1<<20 {
+		return nil, fmt.Errorf("bdf: bounding box %dx%d is too large", width, height)
+	}
+	return box, nil
+}
+
+func parseBDF(data []byte) (*Font, error) {
+	f := &Font{Format: "BDF", Properties: map[string]string{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+
+	var box []int
+	var glyph *Glyph
+	codes := []int{}
+	code, advance := -1, 0
+	inProps, inBitmap := false, false
+	line := 0
+	for scanner.Scan() {
+		line++
+		key, args := bdfFields(scanner.Text())
+		var err error
+
+		switch {
+		case inBitmap && key != "ENDCHAR":
+			row, err := hex.DecodeString(key)
+			if err != nil {
+				return nil, fmt.Errorf("bdf: line %d: %w", line, err)
+			}
+			stride := glyph.stride()
+			glyph.Bits = append(glyph.Bits, append(row, make([]byte, stride)...)[:stride]...)
+		case inProps && key != "ENDPROPERTIES":
+			f.Properties[key] = strings.Join(args, " ")
+		case key == "STARTPROPERTIES":
+			inProps = true
+		case key == "ENDPROPERTIES":
+			inProps = false
+		case key == "FONT":
+			f.Properties["FONT"] = strings.Join(args, " ")
+		case key == "FONTBOUNDINGBOX":
+			box, err = bdfBox(args)
+		case key == "STARTCHAR":
+			glyph = &Glyph{Name: strings.Join(args, " "), Advance: advance}
+			code = -1
+			if box != nil {
+				glyph.Width, glyph.Height, glyph.X, glyph.Y = box[0], box[1], box[2], box[3]
+			}
+		case key == "DWIDTH" && glyph == nil:
+			// Font wide default of BDF 2.2
+			var width []int
+			width, err = bdfInts(args, 1)
+			if err == nil {
+				advance = width[0]
+			}
+		case glyph == nil:
+		case key == "ENCODING":
+			var enc []int
+			enc, err = bdfInts(args, 1)
+			if err == nil {
+				code = enc[0]
+			}
+		case key == "DWIDTH":
+			var width []int
+			width, err = bdfInts(args, 1)
+			if err == nil {
+				glyph.Advance = width[0]
+			}
+		case key == "BBX":
+			var bbx []int
+			bbx, err = bdfBox(args)
+			if err == nil {
+				glyph.Width, glyph.Height, glyph.X, glyph.Y = bbx[0], bbx[1], bbx[2], bbx[3]
+			}
+		case key == "BITMAP":
+			inBitmap = true
+		case key == "ENDCHAR":
+			inBitmap = false
+			for len(glyph.Bits) < glyph.stride()*glyph.Height {
+				glyph.Bits = append(glyph.Bits, 0)
+			}
+			f.Glyphs = append(f.Glyphs, *glyph)
+			codes = append(codes, code)
+			glyph = nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("bdf: %w", err)
+	}
+	if len(f.Glyphs) == 0 {
+		return nil, ErrTruncated
+	}
+
+	f.bdfMetrics(box)
+	toRune := charsetMapper(f.Properties["CHARSET_REGISTRY"], f.Properties["CHARSET_ENCODING"])
+	for idx := range f.Glyphs {
+		if r, ok := toRune(codes[idx]); ok {
+			f.Glyphs[idx].Runes = []rune{r}
+		}
+	}
+	return f, nil
+}
+
+// Fills in the names and vertical metrics from the properties, which PCF
+// shares with BDF
+func (f *Font) bdfMetrics(box []int) {
+	// Fields of the XLFD stand in for missing properties
+	if xlfd := strings.Split(f.Properties["FONT"], "-"); len(xlfd) == 15 {
+		for idx, key := range map[int]string{2: "FAMILY_NAME", 3: "WEIGHT_NAME", 4: "SLANT", 13: "CHARSET_REGISTRY", 14: "CHARSET_ENCODING"} {
+			if f.Properties[key] == "" && xlfd[idx] != "" {
+				f.Properties[key] = xlfd[idx]
+			}
+		}
+	}
+	f.Family = f.Properties["FAMILY_NAME"]
+	f.xlfdStyle()
+
+	ascent, errA := strconv.Atoi(f.Properties["FONT_ASCENT"])
+	descent, errD := strconv.Atoi(f.Properties["FONT_DESCENT"])
+	if errA == nil && errD == nil {
+		f.Ascent, f.Descent = ascent, descent
+	} else if box != nil {
+		f.Ascent, f.Descent = box[1]+box[3], -box[3]
+	}
+	if f.Ascent+f.Descent <= 0 {
+		for _, g := range f.Glyphs {
+			f.Ascent = max(f.Ascent, g.Y+g.Height)
+			f.Descent = max(f.Descent, -g.Y)
+		}
+	}
+}
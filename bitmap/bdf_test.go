@@ -0,0 +1,49 @@
+package bitmap
+
+import (
+	"fmt"
+	"testing"
+)
+
+func bdfFixture(bbx string) []byte {
+	return fmt.Appendf(nil, `STARTFONT 2.1
+FONT -misc-fixture-medium-r-normal--8-80-75-75-c-80-iso10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 8 8 0 -2
+STARTPROPERTIES 2
+FONT_ASCENT 6
+FONT_DESCENT 2
+ENDPROPERTIES
+CHARS 1
+STARTCHAR A
+ENCODING 65
+DWIDTH 8 0
+BBX %s
+BITMAP
+F0
+0F
+ENDCHAR
+ENDFONT
+`, bbx)
+}
+
+func TestParseBDF(t *testing.T) {
+	f, err := Parse(bdfFixture("8 2 0 0"), "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Glyphs) != 1 {
+		t.Fatalf("got %d glyphs, want 1", len(f.Glyphs))
+	}
+	if a := f.Glyphs[0]; len(a.Runes) != 1 || a.Runes[0] != 'A' || !a.Pixel(0, 0) || a.Pixel(0, 1) {
+		t.Errorf("got runes %q with bits % X", a.Runes, a.Bits)
+	}
+}
+
+func TestParseBDFBounds(t *testing.T) {
+	for _, bbx := range []string{"-20 1 0 0", "8 -1 0 0", "100000 100000 0 0"} {
+		if _, err := Parse(bdfFixture(bbx), "fallback"); err == nil {
+			t.Errorf("BBX %s: expected an error", bbx)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package bitmap reads X11 BDF and PCF fonts and Linux console PSF fonts,
+// which Qt can't load, and converts them to TrueType outlines made of one
+// square per pixel.
+package bitmap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrUnknown   = errors.New("bitmap: not a BDF, PCF or PSF font")
+	ErrTruncated = errors.New("bitmap: font is truncated")
+)
+
+type Glyph struct {
+	Name string
+	// Every character mapped to the glyph, PSF tables can list several
+	Runes []rune
+	// Size of the bitmap and the offset of its bottom left corner from the
+	// origin, y going up
+	Width, Height int
+	X, Y          int
+	Advance       int
+	// Rows top to bottom, each padded to whole bytes, most significant bit
+	// first
+	Bits []byte
+}
+
+func (g *Glyph) stride() int {
+	return (g.Width + 7) / 8
+}
+
+// Whether the pixel at column x of row y, counted from the top, is set
+func (g *Glyph) Pixel(x, y int) bool {
+	off := y*g.stride() + x/8
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height || off >= len(g.Bits) {
+		return false
+	}
+	return g.Bits[off]&(0x80>>(x%8)) != 0
+}
+
+type Font struct {
+	// "BDF", "PCF", "PSF1" or "PSF2"
+	Format string
+	Family string
+	Style  string
+	// Height of the em in pixels, the ascent and descent added up
+	Ascent, Descent int
+	// BDF and PCF properties, eg FOUNDRY or COPYRIGHT
+	Properties map[string]string
+	Glyphs     []Glyph
+}
+
+// Whether `data` looks like a font this package reads, gzipped or not
+func Detect(data []byte) bool {
+	data = gunzip(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("STARTFONT")),
+		bytes.HasPrefix(data, []byte("\x01fcp")),
+		bytes.HasPrefix(data, []byte{0x36, 0x04}),
+		bytes.HasPrefix(data, []byte{0x72, 0xb5, 0x4a, 0x86}):
+		return true
+	}
+	return false
+}
+
+// Console and X11 fonts are usually installed gzipped
+func gunzip(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return data
+	}
+	ret, err := io.ReadAll(r)
+	if err != nil {
+		return data
+	}
+	return ret
+}
+
+// Reads a font, `name` being the family to use when the file names none,
+// such as the file name of a PSF font
+func Parse(data []byte, name string) (*Font, error) {
+	data = gunzip(data)
+	var f *Font
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("STARTFONT")):
+		f, err = parseBDF(data)
+	case bytes.HasPrefix(data, []byte("\x01fcp")):
+		f, err = parsePCF(data)
+	case bytes.HasPrefix(data, []byte{0x36, 0x04}), bytes.HasPrefix(data, []byte{0x72, 0xb5, 0x4a, 0x86}):
+		f, err = parsePSF(data)
+	default:
+		return nil, ErrUnknown
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Family == "" {
+		f.Family = name
+	}
+	if f.Style == "" {
+		f.Style = "Regular"
+	}
+	return f, nil
+}
+
+// Names the style after the XLFD weight and slant properties
+func (f *Font) xlfdStyle() {
+	weight := f.Properties["WEIGHT_NAME"]
+	if strings.EqualFold(weight, "medium") || strings.EqualFold(weight, "normal") {
+		weight = ""
+	}
+	slant := map[string]string{"I": "Italic", "O": "Oblique"}[strings.ToUpper(f.Properties["SLANT"])]
+	f.Style = strings.TrimSpace(cases.Title(language.English).String(weight) + " " + slant)
+}
+
+// Maps an encoded character to Unicode using the XLFD charset, eg
+// ISO8859-2. Multi-byte charsets other than ISO10646 aren't mapped.
+func charsetMapper(registry, enc string) func(code int) (rune, bool) {
+	registry = strings.ToUpper(registry)
+	if registry == "ISO10646" || registry == "" || (registry == "ISO8859" && enc == "1") {
+		return func(code int) (rune, bool) {
+			return rune(code), code >= 0
+		}
+	}
+
+	var decoder encoding.Encoding
+	switch {
+	case registry == "ISO8859":
+		decoder, _ = ianaindex.IANA.Encoding("ISO-8859-" + enc)
+	case registry == "IBM" && enc == "CP437":
+		decoder = charmap.CodePage437
+	default:
+		decoder, _ = ianaindex.IANA.Encoding(registry + "-" + enc)
+	}
+	if decoder == nil {
+		return func(code int) (rune, bool) {
+			return 0, false
+		}
+	}
+	table := decoder.NewDecoder()
+	return func(code int) (rune, bool) {
+		if code < 0 || code > 0xFF {
+			return 0, false
+		}
+		out, err := table.Bytes([]byte{byte(code)})
+		r := []rune(string(out))
+		if err != nil || len(r) != 1 || r[0] == '�' {
+			return 0, false
+		}
+		return r[0], true
+	}
+}
@@ -0,0 +1,220 @@
+package bitmap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	pcfProperties      = 1 << 0
+	pcfAccelerators    = 1 << 1
+	pcfMetrics         = 1 << 2
+	pcfBitmaps         = 1 << 3
+	pcfBDFEncodings    = 1 << 5
+	pcfGlyphNames      = 1 << 7
+	pcfBDFAccelerators = 1 << 8
+)
+
+// A PCF table, read in the byte order its format asks for
+type pcfTable struct {
+	format uint32
+	b      []byte
+	order  binary.ByteOrder
+}
+
+func (t *pcfTable) u8(off int) int {
+	if off < 0 || off >= len(t.b) {
+		return 0
+	}
+	return int(t.b[off])
+}
+
+func (t *pcfTable) u16(off int) int {
+	if off < 0 || off+2 > len(t.b) {
+		return 0
+	}
+	return int(t.order.Uint16(t.b[off:]))
+}
+
+func (t *pcfTable) i16(off int) int {
+	return int(int16(t.u16(off)))
+}
+
+func (t *pcfTable) i32(off int) int {
+	if off < 0 || off+4 > len(t.b) {
+		return 0
+	}
+	return int(int32(t.order.Uint32(t.b[off:])))
+}
+
+// A NUL terminated string from a string pool
+func (t *pcfTable) str(pool, off int) string {
+	start := pool + off
+	if start < 0 || start >= len(t.b) {
+		return ""
+	}
+	end := start
+	for end < len(t.b) && t.b[end] != 0 {
+		end++
+	}
+	return string(t.b[start:end])
+}
+
+func parsePCF(data []byte) (*Font, error) {
+	le := binary.LittleEndian
+	if len(data) < 8 {
+		return nil, ErrTruncated
+	}
+	tables := map[uint32]*pcfTable{}
+	for idx := range int(le.Uint32(data[4:])) {
+		rec := 8 + 16*idx
+		if rec+16 > len(data) {
+			return nil, ErrTruncated
+		}
+		// Type, format, size and offset
+		kind, size, off := le.Uint32(data[rec:]), int(le.Uint32(data[rec+8:])), int(le.Uint32(data[rec+12:]))
+		if off+size > len(data) || size < 4 {
+			return nil, ErrTruncated
+		}
+		t := &pcfTable{format: le.Uint32(data[off:]), b: data[off : off+size], order: binary.LittleEndian}
+		if t.format&(1<<2) != 0 {
+			t.order = binary.BigEndian
+		}
+		tables[kind] = t
+	}
+	for _, kind := range []uint32{pcfProperties, pcfMetrics, pcfBitmaps, pcfBDFEncodings} {
+		if tables[kind] == nil {
+			return nil, fmt.Errorf("pcf: table %d is missing: %w", kind, ErrTruncated)
+		}
+	}
+
+	f := &Font{Format: "PCF", Properties: map[string]string{}}
+	props := tables[pcfProperties]
+	num := props.i32(4)
+	if num < 0 || 8+9*num > len(props.b) {
+		return nil, ErrTruncated
+	}
+	pool := 8 + 9*num + (4-num&3)&3 + 4
+	for idx := range num {
+		rec := 8 + 9*idx
+		value := strconv.Itoa(props.i32(rec + 5))
+		if props.u8(rec+4) != 0 {
+			value = props.str(pool, props.i32(rec+5))
+		}
+		f.Properties[props.str(pool, props.i32(rec))] = value
+	}
+
+	metrics := tables[pcfMetrics]
+	var glyphs []Glyph
+	if metrics.format&0x100 != 0 {
+		if 6+5*metrics.u16(4) > len(metrics.b) {
+			return nil, ErrTruncated
+		}
+		for idx := range metrics.u16(4) {
+			rec := 6 + 5*idx
+			left, right := metrics.u8(rec)-0x80, metrics.u8(rec+1)-0x80
+			ascent, descent := metrics.u8(rec+3)-0x80, metrics.u8(rec+4)-0x80
+			glyphs = append(glyphs, Glyph{
+				Width: right - left, Height: ascent + descent,
+				X: left, Y: -descent, Advance: metrics.u8(rec+2) - 0x80,
+			})
+		}
+	} else {
+		if num := metrics.i32(4); num < 0 || 8+12*num > len(metrics.b) {
+			return nil, ErrTruncated
+		}
+		for idx := range metrics.i32(4) {
+			rec := 8 + 12*idx
+			left, right := metrics.i16(rec), metrics.i16(rec+2)
+			ascent, descent := metrics.i16(rec+6), metrics.i16(rec+8)
+			glyphs = append(glyphs, Glyph{
+				Width: right - left, Height: ascent + descent,
+				X: left, Y: -descent, Advance: metrics.i16(rec + 4),
+			})
+		}
+	}
+
+	bitmaps := tables[pcfBitmaps]
+	count := bitmaps.i32(4)
+	if count != len(glyphs) {
+		return nil, fmt.Errorf("pcf: %d bitmaps for %d glyphs", count, len(glyphs))
+	}
+	pad := 1 << (bitmaps.format & 3)
+	unit := 1 << (bitmaps.format >> 4 & 3)
+	msbBits := bitmaps.format&(1<<3) != 0
+	msbBytes := bitmaps.format&(1<<2) != 0
+	base := 8 + 4*count + 16
+	for idx := range glyphs {
+		g := &glyphs[idx]
+		if g.Width <= 0 || g.Height <= 0 {
+			g.Width, g.Height = max(g.Width, 0), max(g.Height, 0)
+			continue
+		}
+		rowSize := (g.stride() + pad - 1) / pad * pad
+		start := base + bitmaps.i32(8+4*idx)
+		if start < 0 || start+rowSize*g.Height > len(bitmaps.b) {
+			return nil, ErrTruncated
+		}
+		raw := make([]byte, rowSize*g.Height)
+		copy(raw, bitmaps.b[start:])
+
+		// Normalized to most significant bit and byte first, like libXfont
+		if !msbBits {
+			for n, b := range raw {
+				raw[n] = bits.Reverse8(b)
+			}
+		}
+		if msbBits != msbBytes && unit > 1 {
+			for n := 0; n+unit <= len(raw); n += unit {
+				for lo, hi := n, n+unit-1; lo < hi; lo, hi = lo+1, hi-1 {
+					raw[lo], raw[hi] = raw[hi], raw[lo]
+				}
+			}
+		}
+		for row := range g.Height {
+			g.Bits = append(g.Bits, raw[row*rowSize:row*rowSize+g.stride()]...)
+		}
+	}
+
+	if names := tables[pcfGlyphNames]; names != nil {
+		num := names.i32(4)
+		pool := 8 + 4*num + 4
+		for idx := range min(num, len(glyphs)) {
+			glyphs[idx].Name = names.str(pool, names.i32(8+4*idx))
+		}
+	}
+
+	toRune := charsetMapper(f.Properties["CHARSET_REGISTRY"], f.Properties["CHARSET_ENCODING"])
+	enc := tables[pcfBDFEncodings]
+	minByte2, maxByte2 := enc.i16(4), enc.i16(6)
+	minByte1, maxByte1 := enc.i16(8), enc.i16(10)
+	cols := maxByte2 - minByte2 + 1
+	rows := maxByte1 - minByte1 + 1
+	if rows <= 0 || cols <= 0 || 14+2*rows*cols > len(enc.b) {
+		return nil, fmt.Errorf("pcf: encodings: %w", ErrTruncated)
+	}
+	for byte1 := minByte1; byte1 <= maxByte1; byte1++ {
+		for byte2 := minByte2; byte2 <= maxByte2; byte2++ {
+			idx := enc.u16(14 + 2*((byte1-minByte1)*cols+byte2-minByte2))
+			if idx == 0xFFFF || idx >= len(glyphs) {
+				continue
+			}
+			if r, ok := toRune(byte1<<8 | byte2); ok {
+				glyphs[idx].Runes = append(glyphs[idx].Runes, r)
+			}
+		}
+	}
+	f.Glyphs = glyphs
+
+	f.bdfMetrics(nil)
+	accel := tables[pcfBDFAccelerators]
+	if accel == nil {
+		accel = tables[pcfAccelerators]
+	}
+	if accel != nil && f.Properties["FONT_ASCENT"] == "" {
+		f.Ascent, f.Descent = accel.i32(12), accel.i32(16)
+	}
+	return f, nil
+}
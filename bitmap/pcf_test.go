@@ -0,0 +1,117 @@
+package bitmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// A big endian table, its format word in front
+func pcfFixtureTable(format uint32, fields ...any) []byte {
+	buf := binary.LittleEndian.AppendUint32(nil, format)
+	for _, field := range fields {
+		switch v := field.(type) {
+		case string:
+			buf = append(buf, v...)
+		default:
+			buf, _ = binary.Append(buf, binary.BigEndian, v)
+		}
+	}
+	for len(buf)%4 != 0 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// Two glyphs, A as an 8x2 bitmap and B as a 3x1 one, C left unmapped
+func pcfFixture() []byte {
+	pool := "FAMILY_NAME\x00Fixture\x00CHARSET_REGISTRY\x00ISO10646\x00CHARSET_ENCODING\x001\x00"
+	props := pcfFixtureTable(0x0C,
+		int32(3),
+		int32(0), int8(1), int32(12),
+		int32(20), int8(1), int32(37),
+		int32(46), int8(1), int32(63),
+		[]byte{0}, int32(len(pool)), pool,
+	)
+	metrics := pcfFixtureTable(0x10C,
+		int16(2),
+		[]uint8{0x80, 0x88, 0x88, 0x82, 0x80},
+		[]uint8{0x80, 0x83, 0x84, 0x81, 0x80},
+	)
+	bitmaps := pcfFixtureTable(0x0E,
+		int32(2), int32(0), int32(8), [4]int32{3, 3, 12, 12},
+		[]byte{0xF0, 0, 0, 0, 0x0F, 0, 0, 0, 0xA0, 0, 0, 0},
+	)
+	encodings := pcfFixtureTable(0x0C,
+		int16(0x41), int16(0x43), int16(0), int16(0), int16(0),
+		[]uint16{0, 1, 0xFFFF},
+	)
+
+	tables := []struct {
+		kind uint32
+		b    []byte
+	}{
+		{pcfProperties, props},
+		{pcfMetrics, metrics},
+		{pcfBitmaps, bitmaps},
+		{pcfBDFEncodings, encodings},
+	}
+	head := []byte("\x01fcp")
+	head = binary.LittleEndian.AppendUint32(head, uint32(len(tables)))
+	body := []byte{}
+	off := len(head) + 16*len(tables)
+	for _, t := range tables {
+		for _, v := range []uint32{t.kind, binary.LittleEndian.Uint32(t.b), uint32(len(t.b)), uint32(off + len(body))} {
+			head = binary.LittleEndian.AppendUint32(head, v)
+		}
+		body = append(body, t.b...)
+	}
+	return append(head, body...)
+}
+
+func TestParsePCF(t *testing.T) {
+	f, err := Parse(pcfFixture(), "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Format != "PCF" || f.Family != "Fixture" {
+		t.Errorf("got %s %q, want PCF \"Fixture\"", f.Format, f.Family)
+	}
+	if len(f.Glyphs) != 2 {
+		t.Fatalf("got %d glyphs, want 2", len(f.Glyphs))
+	}
+
+	a, b := f.Glyphs[0], f.Glyphs[1]
+	if len(a.Runes) != 1 || a.Runes[0] != 'A' || len(b.Runes) != 1 || b.Runes[0] != 'B' {
+		t.Errorf("got runes %q and %q, want A and B", a.Runes, b.Runes)
+	}
+	if a.Width != 8 || a.Height != 2 || a.Advance != 8 {
+		t.Errorf("got A %dx%d advancing %d, want 8x2 advancing 8", a.Width, a.Height, a.Advance)
+	}
+	if !bytes.Equal(a.Bits, []byte{0xF0, 0x0F}) || !bytes.Equal(b.Bits, []byte{0xA0}) {
+		t.Errorf("got bits % X and % X", a.Bits, b.Bits)
+	}
+	if !b.Pixel(0, 0) || b.Pixel(1, 0) || !b.Pixel(2, 0) {
+		t.Error("B should be set, clear, set")
+	}
+}
+
+func TestParsePCFPropertyCount(t *testing.T) {
+	data := pcfFixture()
+	// The property count of the first table, right after the format word
+	off := int(binary.LittleEndian.Uint32(data[8+12:]))
+	binary.BigEndian.PutUint32(data[off+4:], 0x7FFFFFFF)
+	if _, err := Parse(data, "fallback"); err == nil {
+		t.Error("expected an error for a property count past the table")
+	}
+}
+
+func TestParsePCFEncodingRange(t *testing.T) {
+	data := pcfFixture()
+	// The last byte2 of the encodings, the fourth table
+	off := int(binary.LittleEndian.Uint32(data[8+16*3+12:]))
+	binary.BigEndian.PutUint16(data[off+6:], 0x50)
+	if _, err := Parse(data, "fallback"); err == nil {
+		t.Error("expected an error for encodings past the table")
+	}
+}
@@ -0,0 +1,100 @@
+package bitmap
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// PSF has no baseline, this puts it where the VGA fonts have it
+func psfDescent(height int) int {
+	return height / 4
+}
+
+func parsePSF(data []byte) (*Font, error) {
+	le := binary.LittleEndian
+	var count, size, width, height, start int
+	var unicode bool
+	f := &Font{}
+
+	if data[0] == 0x36 {
+		if len(data) < 4 {
+			return nil, ErrTruncated
+		}
+		f.Format = "PSF1"
+		mode := data[2]
+		count, size, width, height, start = 256, int(data[3]), 8, int(data[3]), 4
+		if mode&0x1 != 0 {
+			count = 512
+		}
+		unicode = mode&0x6 != 0
+	} else {
+		if len(data) < 32 {
+			return nil, ErrTruncated
+		}
+		f.Format = "PSF2"
+		start = int(le.Uint32(data[8:]))
+		unicode = le.Uint32(data[12:])&0x1 != 0
+		count, size = int(le.Uint32(data[16:])), int(le.Uint32(data[20:]))
+		height, width = int(le.Uint32(data[24:])), int(le.Uint32(data[28:]))
+	}
+	// Bounded before multiplying, PSF2 counts and sizes come from the file
+	stride := (width + 7) / 8
+	if start > len(data) || size <= 0 || count > (len(data)-start)/size || stride*height > size {
+		return nil, ErrTruncated
+	}
+
+	f.Descent = psfDescent(height)
+	f.Ascent = height - f.Descent
+	for idx := range count {
+		bits := data[start+idx*size : start+idx*size+stride*height]
+		f.Glyphs = append(f.Glyphs, Glyph{
+			Width: width, Height: height, Y: -f.Descent, Advance: width, Bits: bits,
+		})
+	}
+
+	table := data[start+count*size:]
+	switch {
+	case !unicode:
+		// Without a table the glyphs are in the order of the VGA code page
+		for idx := range min(count, 256) {
+			f.Glyphs[idx].Runes = []rune{charmap.CodePage437.DecodeByte(byte(idx))}
+		}
+	case f.Format == "PSF1":
+		// UCS-2 values per glyph, sequences after 0xFFFE, 0xFFFF ending
+		glyph, seq := 0, false
+		for off := 0; off+2 <= len(table) && glyph < count; off += 2 {
+			switch v := le.Uint16(table[off:]); v {
+			case 0xFFFF:
+				glyph, seq = glyph+1, false
+			case 0xFFFE:
+				seq = true
+			default:
+				if !seq {
+					f.Glyphs[glyph].Runes = append(f.Glyphs[glyph].Runes, rune(v))
+				}
+			}
+		}
+	default:
+		// UTF-8 per glyph, sequences after 0xFE, 0xFF ending
+		glyph, seq := 0, false
+		for off := 0; off < len(table) && glyph < count; {
+			switch table[off] {
+			case 0xFF:
+				glyph, seq = glyph+1, false
+				off++
+			case 0xFE:
+				seq = true
+				off++
+			default:
+				r, n := utf8.DecodeRune(table[off:])
+				if !seq && r != utf8.RuneError {
+					f.Glyphs[glyph].Runes = append(f.Glyphs[glyph].Runes, r)
+				}
+				off += n
+			}
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,45 @@
+package bitmap
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// A PSF2 header, its glyphs left for the caller to append
+func psfFixture(count, size, height, width uint32) []byte {
+	data := []byte{0x72, 0xB5, 0x4A, 0x86}
+	for _, v := range []uint32{0, 32, 0, count, size, height, width} {
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+	return data
+}
+
+func TestParsePSF(t *testing.T) {
+	data := append(psfFixture(2, 2, 2, 8), 0xF0, 0x0F, 0xAA, 0x55)
+	f, err := Parse(data, "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Format != "PSF2" || len(f.Glyphs) != 2 {
+		t.Fatalf("got %s with %d glyphs, want PSF2 with 2", f.Format, len(f.Glyphs))
+	}
+	if a := f.Glyphs[0]; a.Width != 8 || a.Height != 2 || !a.Pixel(0, 0) || a.Pixel(0, 1) {
+		t.Errorf("got glyph 0 %dx%d with bits % X", a.Width, a.Height, a.Bits)
+	}
+}
+
+func TestParsePSFBounds(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		count, size, height uint32
+	}{
+		{"overflowing", 0xFFFFFFFF, 0xFFFFFFFF, 1},
+		{"zero size", 0xFFFFFFFF, 0, 0},
+		{"too many", 3, 2, 1},
+	} {
+		data := append(psfFixture(tc.count, tc.size, tc.height, 8), 0, 0, 0, 0)
+		if _, err := Parse(data, "fallback"); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package bitmap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"fontview/sfnt"
+	"math"
+	"slices"
+	"strings"
+)
+
+var be = binary.BigEndian
+
+// Height of the em in pixels, what sizes have to be a multiple of for the
+// pixels to land on the screen's
+func (f *Font) Em() int {
+	return max(f.Ascent+f.Descent, 1)
+}
+
+// Font units per pixel, as fine as the em size limit allows
+func (f *Font) unit() int {
+	return max(1, min(64, 16384/f.Em()))
+}
+
+// The family the converted font is registered as. Bitmap fonts come in one
+// file per size, so the size is part of it.
+func (f *Font) SizedFamily() string {
+	return fmt.Sprintf("%s %dpx", f.Family, f.Em())
+}
+
+// One rectangle per run of set pixels in a row, wound clockwise
+func (f *Font) glyphOutline(g *Glyph) []byte {
+	u := f.unit()
+	var ends []int
+	var xs, ys []int
+	for row := range g.Height {
+		y0 := (g.Y + g.Height - 1 - row) * u
+		for x := 0; x < g.Width; x++ {
+			if !g.Pixel(x, row) {
+				continue
+			}
+			start := x
+			for x < g.Width && g.Pixel(x, row) {
+				x++
+			}
+			x0, x1 := (g.X+start)*u, (g.X+x)*u
+			xs = append(xs, x0, x0, x1, x1)
+			ys = append(ys, y0, y0+u, y0+u, y0)
+			ends = append(ends, len(xs)-1)
+		}
+	}
+	if len(ends) == 0 {
+		return nil
+	}
+
+	b := be.AppendUint16(nil, uint16(len(ends)))
+	b = be.AppendUint16(b, uint16(int16(slices.Min(xs))))
+	b = be.AppendUint16(b, uint16(int16(slices.Min(ys))))
+	b = be.AppendUint16(b, uint16(int16(slices.Max(xs))))
+	b = be.AppendUint16(b, uint16(int16(slices.Max(ys))))
+	for _, end := range ends {
+		b = be.AppendUint16(b, uint16(end))
+	}
+	b = be.AppendUint16(b, 0)
+	for range xs {
+		// On curve, coordinates as words
+		b = append(b, 0x01)
+	}
+	last := 0
+	for _, x := range xs {
+		b = be.AppendUint16(b, uint16(int16(x-last)))
+		last = x
+	}
+	last = 0
+	for _, y := range ys {
+		b = be.AppendUint16(b, uint16(int16(y-last)))
+		last = y
+	}
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// Glyph names for post, made up from the code point where the font has none
+func (f *Font) glyphName(idx int) string {
+	g := &f.Glyphs[idx]
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("[](){}<>/%", r) {
+			return -1
+		}
+		return r
+	}, g.Name)
+	switch {
+	case name != "":
+	case len(g.Runes) > 0 && g.Runes[0] <= 0xFFFF:
+		name = fmt.Sprintf("uni%04X", g.Runes[0])
+	case len(g.Runes) > 0:
+		name = fmt.Sprintf("u%X", g.Runes[0])
+	default:
+		name = fmt.Sprintf("glyph%d", idx)
+	}
+	return name[:min(len(name), 63)]
+}
+
+// Converts the font to TrueType, glyph 0 being an empty .notdef followed by
+// the glyphs in file order
+func (f *Font) Sfnt() []byte {
+	u := f.unit()
+	num := len(f.Glyphs) + 1
+	bold := strings.Contains(f.Style, "Bold")
+	italic := strings.Contains(f.Style, "Italic") || strings.Contains(f.Style, "Oblique")
+
+	var glyf, loca, hmtx []byte
+	loca = be.AppendUint32(loca, 0)
+	loca = be.AppendUint32(loca, 0)
+	hmtx = be.AppendUint32(hmtx, 0)
+	xMin, yMin, xMax, yMax := math.MaxInt16, math.MaxInt16, math.MinInt16, math.MinInt16
+	maxPoints, maxContours, maxAdvance, totalAdvance := 0, 0, 0, 0
+	monospace := true
+	for idx := range f.Glyphs {
+		g := &f.Glyphs[idx]
+		outline := f.glyphOutline(g)
+		glyf = append(glyf, outline...)
+		loca = be.AppendUint32(loca, uint32(len(glyf)))
+
+		advance, lsb := g.Advance*u, 0
+		if len(outline) > 0 {
+			lsb = int(int16(be.Uint16(outline[2:])))
+			xMin, yMin = min(xMin, lsb), min(yMin, int(int16(be.Uint16(outline[4:]))))
+			xMax, yMax = max(xMax, int(int16(be.Uint16(outline[6:])))), max(yMax, int(int16(be.Uint16(outline[8:]))))
+			contours := int(be.Uint16(outline))
+			maxContours = max(maxContours, contours)
+			maxPoints = max(maxPoints, 1+int(be.Uint16(outline[10+2*(contours-1):])))
+		}
+		hmtx = be.AppendUint16(hmtx, uint16(advance))
+		hmtx = be.AppendUint16(hmtx, uint16(int16(lsb)))
+		monospace = monospace && (advance == 0 || maxAdvance == 0 || advance == maxAdvance)
+		maxAdvance = max(maxAdvance, advance)
+		totalAdvance += advance
+	}
+	if xMin > xMax {
+		xMin, yMin, xMax, yMax = 0, 0, 0, 0
+	}
+
+	upem := f.Em() * u
+	ascent, descent := f.Ascent*u, f.Descent*u
+
+	head := be.AppendUint32(nil, 0x00010000)
+	head = be.AppendUint32(head, 0x00010000)
+	head = be.AppendUint32(head, 0)
+	head = be.AppendUint32(head, 0x5F0F3CF5)
+	head = be.AppendUint16(head, 0x000B)
+	head = be.AppendUint16(head, uint16(upem))
+	head = append(head, make([]byte, 16)...)
+	head = be.AppendUint16(head, uint16(int16(xMin)))
+	head = be.AppendUint16(head, uint16(int16(yMin)))
+	head = be.AppendUint16(head, uint16(int16(xMax)))
+	head = be.AppendUint16(head, uint16(int16(yMax)))
+	macStyle := 0
+	if bold {
+		macStyle |= 0x1
+	}
+	if italic {
+		macStyle |= 0x2
+	}
+	head = be.AppendUint16(head, uint16(macStyle))
+	head = be.AppendUint16(head, uint16(f.Em()))
+	head = be.AppendUint16(head, 2)
+	head = be.AppendUint16(head, 1)
+	head = be.AppendUint16(head, 0)
+
+	hhea := be.AppendUint32(nil, 0x00010000)
+	hhea = be.AppendUint16(hhea, uint16(int16(ascent)))
+	hhea = be.AppendUint16(hhea, uint16(int16(-descent)))
+	hhea = be.AppendUint16(hhea, 0)
+	hhea = be.AppendUint16(hhea, uint16(maxAdvance))
+	hhea = be.AppendUint16(hhea, uint16(int16(min(xMin, 0))))
+	hhea = be.AppendUint16(hhea, 0)
+	hhea = be.AppendUint16(hhea, uint16(int16(xMax)))
+	hhea = be.AppendUint16(hhea, 1)
+	hhea = append(hhea, make([]byte, 14)...)
+	hhea = be.AppendUint16(hhea, uint16(num))
+
+	maxp := be.AppendUint32(nil, 0x00010000)
+	maxp = be.AppendUint16(maxp, uint16(num))
+	maxp = be.AppendUint16(maxp, uint16(maxPoints))
+	maxp = be.AppendUint16(maxp, uint16(maxContours))
+	maxp = be.AppendUint32(maxp, 0)
+	maxp = be.AppendUint16(maxp, 2)
+	maxp = append(maxp, make([]byte, 16)...)
+
+	return sfnt.Assemble(map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"maxp": maxp,
+		"hmtx": hmtx,
+		"glyf": glyf,
+		"loca": loca,
+		"cmap": f.cmapTable(),
+		"post": f.postTable(monospace),
+		"OS/2": f.os2Table(totalAdvance/max(len(f.Glyphs), 1), bold, italic),
+		"name": f.nameTable(),
+	})
+}
+
+// A format 12 subtable under both Unicode platforms
+func (f *Font) cmapTable() []byte {
+	mapping := map[rune]int{}
+	for idx, g := range f.Glyphs {
+		for _, r := range g.Runes {
+			if _, ok := mapping[r]; !ok {
+				mapping[r] = idx + 1
+			}
+		}
+	}
+	runes := []rune{}
+	for r := range mapping {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+
+	var groups []byte
+	count := 0
+	for idx := 0; idx < len(runes); {
+		start := idx
+		for idx+1 < len(runes) && runes[idx+1] == runes[idx]+1 && mapping[runes[idx+1]] == mapping[runes[idx]]+1 {
+			idx++
+		}
+		groups = be.AppendUint32(groups, uint32(runes[start]))
+		groups = be.AppendUint32(groups, uint32(runes[idx]))
+		groups = be.AppendUint32(groups, uint32(mapping[runes[start]]))
+		count++
+		idx++
+	}
+
+	b := be.AppendUint16(nil, 0)
+	b = be.AppendUint16(b, 2)
+	b = append(b, 0, 0, 0, 4, 0, 0, 0, 20)
+	b = append(b, 0, 3, 0, 10, 0, 0, 0, 20)
+	b = be.AppendUint16(b, 12)
+	b = be.AppendUint16(b, 0)
+	b = be.AppendUint32(b, uint32(16+len(groups)))
+	b = be.AppendUint32(b, 0)
+	b = be.AppendUint32(b, uint32(count))
+	return append(b, groups...)
+}
+
+// Version 2, with the names from the font
+func (f *Font) postTable(monospace bool) []byte {
+	b := be.AppendUint32(nil, 0x00020000)
+	b = append(b, make([]byte, 8)...)
+	fixed := uint32(0)
+	if monospace {
+		fixed = 1
+	}
+	b = be.AppendUint32(b, fixed)
+	b = append(b, make([]byte, 16)...)
+
+	b = be.AppendUint16(b, uint16(len(f.Glyphs)+1))
+	b = be.AppendUint16(b, 0)
+	var names []byte
+	for idx := range f.Glyphs {
+		b = be.AppendUint16(b, uint16(258+idx))
+		name := f.glyphName(idx)
+		names = append(names, byte(len(name)))
+		names = append(names, name...)
+	}
+	return append(b, names...)
+}
+
+func (f *Font) os2Table(avgAdvance int, bold, italic bool) []byte {
+	u := f.unit()
+	weight := 400
+	if bold {
+		weight = 700
+	}
+	selection := 0x40 | 0x80
+	switch {
+	case bold && italic:
+		selection = 0x21 | 0x80
+	case bold:
+		selection = 0x20 | 0x80
+	case italic:
+		selection = 0x01 | 0x80
+	}
+	first, last := rune(0xFFFF), rune(0)
+	for _, g := range f.Glyphs {
+		for _, r := range g.Runes {
+			first, last = min(first, r), max(last, r)
+		}
+	}
+
+	prop := func(name string) int {
+		v := 0
+		fmt.Sscan(f.Properties[name], &v)
+		return v * u
+	}
+
+	b := be.AppendUint16(nil, 4)
+	b = be.AppendUint16(b, uint16(avgAdvance))
+	b = be.AppendUint16(b, uint16(weight))
+	b = be.AppendUint16(b, 5)
+	b = be.AppendUint16(b, 0)
+	b = append(b, make([]byte, 20)...)
+	b = be.AppendUint16(b, 0)
+	b = append(b, make([]byte, 10+16)...)
+	b = append(b, "    "...)
+	b = be.AppendUint16(b, uint16(selection))
+	b = be.AppendUint16(b, uint16(min(first, 0xFFFF)))
+	b = be.AppendUint16(b, uint16(min(last, 0xFFFF)))
+	b = be.AppendUint16(b, uint16(int16(f.Ascent*u)))
+	b = be.AppendUint16(b, uint16(int16(-f.Descent*u)))
+	b = be.AppendUint16(b, 0)
+	b = be.AppendUint16(b, uint16(f.Ascent*u))
+	b = be.AppendUint16(b, uint16(f.Descent*u))
+	b = append(b, make([]byte, 8)...)
+	b = be.AppendUint16(b, uint16(int16(prop("X_HEIGHT"))))
+	b = be.AppendUint16(b, uint16(int16(prop("CAP_HEIGHT"))))
+	b = be.AppendUint16(b, 0)
+	b = be.AppendUint16(b, 0x20)
+	b = be.AppendUint16(b, 0)
+	return b
+}
+
+func (f *Font) nameTable() []byte {
+	family := f.SizedFamily()
+	full := family
+	if f.Style != "Regular" {
+		full += " " + f.Style
+	}
+	values := map[uint16]string{
+		1: family,
+		2: f.Style,
+		3: fmt.Sprintf("%s;%s", f.Format, full),
+		4: full,
+		5: "Version 1.0",
+		6: strings.Map(func(r rune) rune {
+			if r <= ' ' || r > '~' || strings.ContainsRune("[](){}<>/%", r) {
+				return -1
+			}
+			return r
+		}, strings.ReplaceAll(full, " ", "-")),
+		10: fmt.Sprintf("Converted from %s by fontview", f.Format),
+	}
+	if copyright := f.Properties["COPYRIGHT"]; copyright != "" {
+		values[0] = copyright
+	}
+	if foundry := f.Properties["FOUNDRY"]; foundry != "" {
+		values[8] = foundry
+	}
+	if xlfd := f.Properties["FONT"]; xlfd != "" {
+		values[10] = fmt.Sprintf("%s, converted from %s by fontview", xlfd, f.Format)
+	}
+	return sfnt.NameTable(values)
+}
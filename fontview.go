@@ -1,11 +1,59 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"fontview/gui"
+	"os"
+	"strings"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := export(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Println("launching...")
 	gui.Launch()
 }
+
+// fontview export -font "Font Awesome" -format png U+F015 U+F0E0-U+F0E9
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	family := flags.String("font", "", "installed font family")
+	style := flags.String("style", "", "style within the family, eg Bold")
+	format := flags.String("format", "svg", "svg or png")
+	size := flags.Int("size", 0, "height in pixels, 0 keeps SVG in font units")
+	padding := flags.Int("padding", 0, "pixels around the glyph, font units for unscaled SVG")
+	fg := flags.String("fg", "#000000", "foreground colour")
+	bg := flags.String("bg", "", "background colour, transparent if empty")
+	dir := flags.String("o", ".", "output directory")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: fontview export -font FAMILY [flags] CHARS...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *family == "" || flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format != "svg" && *format != "png" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *format == "png" && *size == 0 {
+		*size = 256
+	}
+
+	return gui.ExportFiles(*family, *style, strings.Join(flags.Args(), " "), *dir, gui.ExportOptions{
+		Format:     *format,
+		Size:       *size,
+		Padding:    *padding,
+		Foreground: *fg,
+		Background: *bg,
+	})
+}
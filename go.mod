@@ -2,10 +2,11 @@ module fontview
 
 go 1.24.2
 
-require github.com/mappu/miqt v0.10.0
-
 require (
-	golang.org/x/image v0.26.0 // indirect
-	golang.org/x/net v0.40.0 // indirect
-	golang.org/x/text v0.25.0 // indirect
+	github.com/andybalholm/brotli v1.2.0
+	github.com/mappu/miqt v0.10.0
+	golang.org/x/net v0.40.0
+	golang.org/x/text v0.25.0
 )
+
+require golang.org/x/image v0.26.0 // indirect
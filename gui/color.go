@@ -0,0 +1,252 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"fontview/tables"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+var (
+	info_Color    GroupBox[*qt6.QWidget]
+	color_Summary *qt6.QLabel
+	color_Palette *qt6.QComboBox
+	color_Preview *qt6.QLabel
+	color_Layers  *qt6.QTreeWidget
+	color_Notes   *qt6.QLabel
+
+	color_Key       string
+	color_Palettes  []sfnt.Palette
+	color_ignoreEvt = false
+)
+
+// Why the same colour font looks different from one program to the next
+var colorFormat_Notes = map[string]string{
+	"COLRv0": "COLRv0 stacks plain outlines tinted from CPAL. FreeType, DirectWrite, Core Text and browsers all draw it.",
+	"COLRv1": "COLRv1 adds gradients, transforms and blending. Qt before 6.7 and FreeType before 2.13 fall back to the monochrome outline.",
+	"SVG":    "SVG documents are drawn by Firefox, Core Text and DirectWrite. FreeType needs an external renderer, so Qt shows the outline fallback.",
+	"sbix":   "sbix holds PNG strikes at fixed sizes, Apple's emoji format. Other sizes are scaled from the nearest strike and may blur.",
+	"CBDT":   "CBDT holds PNG strikes at fixed sizes, Google's emoji format. Core Text doesn't support it at all.",
+	"EBDT":   "EBDT holds monochrome or greyscale bitmaps, usually used by hinted UI fonts at small sizes only.",
+}
+
+func makeInfo_Color() *qt6.QWidget {
+	widget := info_Color.Init("Color Glyphs", qt6.NewQWidget2())
+	layout := qt6.NewQVBoxLayout(widget)
+	layout.SetContentsMargins(0, 0, 0, 0)
+
+	color_Summary = qt6.NewQLabel2()
+	color_Summary.SetWordWrap(true)
+	color_Summary.SetTextInteractionFlags(qt6.TextSelectableByMouse)
+
+	color_Palette = qt6.NewQComboBox2()
+	color_Palette.SetToolTip("CPAL palette used for the previews")
+	color_Palette.OnCurrentIndexChanged(func(idx int) {
+		if color_ignoreEvt {
+			return
+		}
+		updateInfo_Preview(curNode)
+		updateInfo_Color(curNode)
+	})
+
+	color_Preview = qt6.NewQLabel2()
+	color_Preview.SetAlignment(qt6.AlignCenter)
+
+	color_Layers = qt6.NewQTreeWidget2()
+	color_Layers.SetColumnCount(4)
+	color_Layers.SetHeaderLabels([]string{"#", "Glyph", "Palette", "Paint"})
+	color_Layers.SetRootIsDecorated(false)
+
+	color_Notes = qt6.NewQLabel2()
+	color_Notes.SetWordWrap(true)
+	color_Notes.SetTextInteractionFlags(qt6.TextSelectableByMouse)
+
+	layout.AddWidget(color_Summary.QWidget)
+	layout.AddWidget(color_Palette.QWidget)
+	layout.AddWidget(color_Preview.QWidget)
+	layout.AddWidget2(color_Layers.QWidget, 1)
+	layout.AddWidget(color_Notes.QWidget)
+	return info_Color.group.QWidget
+}
+
+// Refills the palettes when the font changes, keeping the selection if the
+// new font has as many
+func color_UpdatePalettes() {
+	key := fontKey(fontPair.Raw)
+	if key == color_Key {
+		return
+	}
+	color_Key = key
+	color_Palettes = fontPair.Sfnt.Palettes()
+
+	color_ignoreEvt = true
+	defer func() { color_ignoreEvt = false }()
+	cur := color_Palette.CurrentIndex()
+	color_Palette.Clear()
+	for idx, palette := range color_Palettes {
+		label := fmt.Sprintf("Palette %d", idx)
+		if palette.Name != "" {
+			label += ", " + palette.Name
+		}
+		switch {
+		case palette.Type&0x1 != 0:
+			label += " (light background)"
+		case palette.Type&0x2 != 0:
+			label += " (dark background)"
+		}
+		swatch := []*qt6.QColor{}
+		for entry := range palette.Colors {
+			swatch = append(swatch, color_Layer(palette.Colors, sfnt.ColorLayer{Palette: uint16(entry), Alpha: 1}))
+		}
+		color_Palette.AddItem2(qt6.NewQIcon2(color_Swatch(swatch...)), label)
+	}
+	color_Palette.SetCurrentIndex(max(min(cur, len(color_Palettes)-1), 0))
+	color_Palette.SetEnabled(len(color_Palettes) > 1)
+}
+
+func color_Current() []color.NRGBA {
+	idx := color_Palette.CurrentIndex()
+	if idx < 0 || idx >= len(color_Palettes) {
+		return nil
+	}
+	return color_Palettes[idx].Colors
+}
+
+// The colour a layer fills with, the text colour for the foreground index
+// and for entries the palette lacks
+func color_Layer(palette []color.NRGBA, layer sfnt.ColorLayer) *qt6.QColor {
+	if int(layer.Palette) >= len(palette) {
+		ret := qt6.NewQColor6(sakurapine.Text.Normal)
+		ret.SetAlphaF(float32(layer.Alpha))
+		return ret
+	}
+	c := palette[layer.Palette]
+	ret := qt6.NewQColor3(int(c.R), int(c.G), int(c.B))
+	ret.SetAlpha(int(float64(c.A) * layer.Alpha))
+	return ret
+}
+
+// One cell per colour, for palettes and layers
+func color_Swatch(colors ...*qt6.QColor) *qt6.QPixmap {
+	img := qt6.NewQImage3(max(12*len(colors), 1), 12, qt6.QImage__Format_ARGB32_Premultiplied)
+	img.Fill2(qt6.Transparent)
+	painter := qt6.NewQPainter2(img.QPaintDevice)
+	for idx, c := range colors {
+		painter.FillRect5(12*idx, 0, 12, 12, c)
+	}
+	painter.End()
+	return qt6.QPixmap_FromImage(img)
+}
+
+// Paints the layers of a COLR glyph over each other at `size` pixels tall.
+// Gradients are flattened by sfnt, so this is a preview, not the real thing.
+func colorGlyph(raw *qt6.QRawFont, gid uint, layers []sfnt.ColorLayer, palette []color.NRGBA, size int) *qt6.QImage {
+	if len(layers) == 0 {
+		return nil
+	}
+	opt := ExportOptions{Size: size}
+	box := exportLayout(raw, gid, opt)
+	units := qt6.NewQRawFont4(raw)
+	units.SetPixelSize(units.UnitsPerEm())
+
+	w := int(math.Ceil(box.width * box.scale))
+	img := qt6.NewQImage3(max(w, 1), size, qt6.QImage__Format_ARGB32_Premultiplied)
+	img.Fill2(qt6.Transparent)
+	painter := qt6.NewQPainter2(img.QPaintDevice)
+	painter.SetRenderHint(qt6.QPainter__Antialiasing)
+	painter.Scale(box.scale, box.scale)
+	painter.Translate2(-box.left, -box.top)
+	for _, layer := range layers {
+		painter.FillPath(units.PathForGlyph(uint(layer.Glyph)), qt6.NewQBrush3(color_Layer(palette, layer)))
+	}
+	painter.End()
+	return img
+}
+
+func updateInfo_Color(node tables.Node) {
+	color_UpdatePalettes()
+	color_Layers.Clear()
+	color_Preview.Clear()
+
+	font := fontPair.Sfnt
+	formats := font.ColorFormats()
+	fam := fontKey(fontPair.Raw)
+	if len(formats) == 0 {
+		color_Summary.SetText(fmt.Sprintf("<b>%s</b> has no color glyphs", fam))
+		color_Notes.SetText("")
+		color_Palette.SetVisible(false)
+		return
+	}
+	color_Summary.SetText(fmt.Sprintf("<b>%s</b>: %s", fam, strings.Join(formats, ", ")))
+	color_Palette.SetVisible(len(color_Palettes) > 0)
+
+	notes := []string{}
+	for _, format := range formats {
+		if note, ok := colorFormat_Notes[format]; ok {
+			notes = append(notes, note)
+		}
+	}
+
+	raw := qt6.NewQRawFont4(fontPair.Raw)
+	gids := raw.GlyphIndexesForString(string(node.Point))
+	if len(gids) == 0 || gids[0] == 0 {
+		info_Color.group.SetTitle(fmt.Sprintf("Color Glyphs (U+%s is not in the font)", node.Code))
+		color_Notes.SetText(strings.Join(notes, "<br><br>"))
+		return
+	}
+
+	glyph := font.GlyphColor(uint16(gids[0]))
+	kinds := []string{}
+	if glyph.COLR >= 0 {
+		kinds = append(kinds, fmt.Sprintf("COLRv%d, %d layers", glyph.COLR, len(glyph.Layers)))
+	}
+	if glyph.SVG {
+		kinds = append(kinds, "SVG")
+	}
+	kinds = append(kinds, glyph.Bitmaps...)
+	if len(kinds) == 0 {
+		kinds = append(kinds, "outline only")
+	}
+	info_Color.group.SetTitle(fmt.Sprintf("Color Glyphs (U+%s: %s)", node.Code, strings.Join(kinds, "; ")))
+
+	if len(glyph.Bitmaps) > 0 && !glyph.Outline && glyph.COLR < 0 && !glyph.SVG {
+		notes = slices.Insert(notes, 0, fmt.Sprintf(
+			"<b>Glyph %d only has a bitmap strike.</b> Renderers without bitmap support draw nothing, "+
+				"and sizes other than %s are scaled from a strike.",
+			gids[0], strings.Join(glyph.Bitmaps, ", "),
+		))
+	}
+	color_Notes.SetText(strings.Join(notes, "<br><br>"))
+
+	if glyph.COLR < 0 {
+		return
+	}
+	palette := color_Current()
+	for idx, layer := range glyph.Layers {
+		item := qt6.NewQTreeWidgetItem()
+		item.SetText(0, fmt.Sprint(idx))
+		item.SetText(1, fmt.Sprint(layer.Glyph))
+		item.SetText(3, layer.Paint)
+		if layer.Palette == sfnt.Foreground {
+			item.SetText(2, "text color")
+		} else {
+			item.SetText(2, fmt.Sprint(layer.Palette))
+		}
+		if layer.Alpha < 1 {
+			item.SetText(3, fmt.Sprintf("%s, %.0f%% alpha", layer.Paint, layer.Alpha*100))
+		}
+		item.SetIcon(2, qt6.NewQIcon2(color_Swatch(color_Layer(palette, layer))))
+		color_Layers.AddTopLevelItem(item)
+	}
+	color_Layers.ResizeColumnToContents(0)
+	color_Layers.ResizeColumnToContents(1)
+
+	if img := colorGlyph(raw, gids[0], glyph.Layers, palette, 156); img != nil {
+		color_Preview.SetPixmap(qt6.QPixmap_FromImage(img))
+	}
+}
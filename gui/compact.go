@@ -0,0 +1,109 @@
+package gui
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	tbl_compact  = false
+	compactRunes = []rune{}
+	compactCache = map[string][]rune{}
+	compactMut   sync.Mutex
+)
+
+// Maps a cell of the grid to the code point shown in it. Row is counted from
+// the top of the whole sheet, not the visible part. False if the cell is past
+// the last glyph in compact mode.
+func cellRune(row, col int) (rune, bool) {
+	if !tbl_compact {
+		return rune(16*row + col), true
+	}
+
+	idx := 16*row + col
+	if idx < 0 || idx >= len(compactRunes) {
+		return -1, false
+	}
+	return compactRunes[idx], true
+}
+
+// The inverse of cellRune. In compact mode unsupported code points map to the
+// cell of the next supported one.
+func runeCell(r rune) (row, col int) {
+	if !tbl_compact {
+		return int(r) / 16, int(r) % 16
+	}
+
+	idx, _ := slices.BinarySearch(compactRunes, r)
+	idx = min(idx, max(len(compactRunes)-1, 0))
+	return idx / 16, idx % 16
+}
+
+func rowHeader(row int) string {
+	if !tbl_compact {
+		return fmt.Sprintf("%03X_", row)
+	}
+
+	r, ok := cellRune(row, 0)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%04X", r)
+}
+
+// From the cmap, which unlike the QRawFont can be read off the main thread
+func supportedRunes(fam string, cmap map[rune]uint16) []rune {
+	compactMut.Lock()
+	ret, ok := compactCache[fam]
+	compactMut.Unlock()
+	if ok {
+		return ret
+	}
+
+	ret = []rune{}
+	for r, gid := range cmap {
+		if gid != 0 {
+			ret = append(ret, r)
+		}
+	}
+	slices.Sort(ret)
+
+	compactMut.Lock()
+	compactCache[fam] = ret
+	compactMut.Unlock()
+	return ret
+}
+
+func updateCompact() {
+	raw := fontPair.Raw
+	fam, cmap := fontKey(raw), currentCmap()
+	go func() {
+		runes := supportedRunes(fam, cmap)
+		mainthread.Wait(func() {
+			if raw != fontPair.Raw || !tbl_compact {
+				return
+			}
+
+			compactRunes = runes
+			tableScroller.SetMaximum(len(compactRunes) / 16)
+			scrollToRune(curNode.Point)
+			renderGlyphs()
+		})
+	}()
+}
+
+func toggleCompact(checked bool) {
+	point := curNode.Point
+	tbl_compact = checked
+	if checked {
+		updateCompact()
+		return
+	}
+
+	tableScroller.SetMaximum(int(blocks[len(blocks)-1].End) / 16)
+	UpdateRealFont()
+	scrollToRune(point)
+}
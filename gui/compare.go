@@ -0,0 +1,361 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"fontview/sfnt"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+type CompareDelta struct {
+	Block               string
+	Start               rune
+	OnlyLeft, OnlyRight int
+	Both                int
+}
+
+var (
+	cmpPanel    *qt6.QDockWidget
+	cmp_FontBox *qt6.QFontComboBox
+	cmp_Table   *qt6.QTableWidget
+	cmp_Summary *qt6.QLabel
+	cmp_Blocks  *qt6.QTreeWidget
+	cmp_Real    *qt6.QFont
+	cmp_Raw     *qt6.QRawFont
+	cmp_Cmap    map[rune]uint16
+	cmp_Cancel  context.CancelFunc
+	// The pair of fonts the block deltas were last counted for
+	cmp_Counted string
+
+	tbl_compare    = false
+	cmp_ignoreEvt  = false
+	cmp_ignoreCell = false
+)
+
+// The font selector of the right hand grid, next to the main one so both
+// grids start at the same height
+func makeCompare_Font() *qt6.QWidget {
+	cmp_FontBox = qt6.NewQFontComboBox(nil)
+	cmp_FontBox.SetToolTip("Font to compare against")
+	cmp_FontBox.SetVisible(false)
+	cmp_FontBox.OnCurrentFontChanged(func(_ *qt6.QFont) {
+		if tbl_compare {
+			updateCompare()
+		}
+	})
+	return cmp_FontBox.QWidget
+}
+
+func makeCompare_Table() *qt6.QWidget {
+	cmp_Table = qt6.NewQTableWidget(nil)
+	cmp_Table.HorizontalHeader().SetSectionResizeMode(qt6.QHeaderView__Fixed)
+	cmp_Table.VerticalHeader().SetSectionResizeMode(qt6.QHeaderView__Fixed)
+	cmp_Table.VerticalHeader().SetVisible(false)
+	cmp_Table.SetVerticalScrollBarPolicy(qt6.ScrollBarAlwaysOff)
+	cmp_Table.SetHorizontalScrollBarPolicy(qt6.ScrollBarAlwaysOff)
+	cmp_Table.HorizontalHeader().SetFont(monoFont)
+	cmp_Table.SetSelectionBehavior(qt6.QAbstractItemView__SelectItems)
+	cmp_Table.SetSelectionMode(qt6.QAbstractItemView__SingleSelection)
+	cmp_Table.SetColumnCount(16)
+	for c := range 16 {
+		cmp_Table.SetHorizontalHeaderItem(c, qt6.NewQTableWidgetItem2(fmt.Sprintf("%X", c)))
+	}
+
+	// Both grids share the scroll position and selection of the main one
+	cmp_Table.OnKeyPressEvent(tbl_KeyEvt)
+	cmp_Table.OnScrollContentsBy(cmp_ScrollEvt)
+	cmp_Table.OnCurrentCellChanged(func(row, col, _, _ int) {
+		if cmp_ignoreCell || row < 0 {
+			return
+		}
+		tableWidget.SetCurrentCell(row, col)
+	})
+	cmp_Table.SetVisible(false)
+	return cmp_Table.QWidget
+}
+
+func MakeCompare() *qt6.QDockWidget {
+	cmpPanel = qt6.NewQDockWidget2("Comparison")
+	cmpPanel.SetAllowedAreas(
+		qt6.BottomDockWidgetArea |
+			qt6.RightDockWidgetArea |
+			qt6.LeftDockWidgetArea,
+	)
+
+	widget := qt6.NewQWidget2()
+	layout := qt6.NewQVBoxLayout(widget)
+	cmp_Summary = qt6.NewQLabel2()
+	cmp_Summary.SetWordWrap(true)
+
+	cmp_Blocks = qt6.NewQTreeWidget2()
+	cmp_Blocks.SetColumnCount(4)
+	cmp_Blocks.SetRootIsDecorated(false)
+	cmp_Blocks.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		onLink(fmt.Sprint(item.Data(0, int(qt6.UserRole)).ToInt()))
+	})
+
+	layout.AddWidget(cmp_Summary.QWidget)
+	layout.AddWidget2(cmp_Blocks.QWidget, 1)
+	cmpPanel.SetWidget(widget)
+	cmpPanel.SetVisible(false)
+	return cmpPanel
+}
+
+func toggleCompare(checked bool) {
+	tbl_compare = checked
+	cmp_FontBox.SetVisible(checked)
+	cmp_Table.SetVisible(checked)
+	cmpPanel.SetVisible(checked)
+	if !checked {
+		if cmp_Cancel != nil {
+			cmp_Cancel()
+		}
+		renderGlyphs()
+		return
+	}
+	cmp_Resize()
+	updateCompare()
+}
+
+func updateCompare() {
+	updateCompare_Font()
+	renderGlyphs()
+	updateCompare_Blocks()
+}
+
+// Loads the right hand font at the pixel size of the main one
+func updateCompare_Font() {
+	family := cmp_FontBox.CurrentFont().Family()
+	cmp_Real = qt6.NewQFont2(family)
+	cmp_Real.SetStyleStrategy(qt6.QFont__NoFontMerging)
+	bitmapFont(cmp_Real, family, fontPair.Real.PixelSize())
+	cmp_Raw = qt6.QRawFont_FromFont(cmp_Real)
+	cmp_Cmap = fontCmap(sfnt.New(cmp_Raw.FontTable), cmp_Raw)
+}
+
+func cmp_Resize() {
+	rows := tableWidget.RowCount()
+	cmp_Table.SetRowCount(rows)
+	for column := range 16 {
+		cmp_Table.SetColumnWidth(column, tableWidget.ColumnWidth(column))
+	}
+	if scroll := cmp_Table.VerticalScrollBar(); scroll.Value() != rows/3 {
+		cmp_ignoreEvt = true
+		scroll.SetValue(rows / 3)
+	}
+}
+
+func cmp_ScrollEvt(super func(dx int, dy int), dx int, dy int) {
+	if cmp_ignoreEvt {
+		cmp_ignoreEvt = false
+		return
+	}
+
+	cmp_ignoreEvt = true
+	sheetNew := max(min(tableScroller.Value()-dy, tableScroller.Maximum()), tableScroller.Minimum())
+	tableScroller.SetValue(sheetNew)
+	cmp_Table.VerticalScrollBar().SetValue(cmp_Table.RowCount() / 3)
+	super(dx, 0)
+}
+
+func cmp_Supports(r rune) bool {
+	return cachedSupports(fontKey(cmp_Raw), cmp_Raw, r)
+}
+
+// Background of a cell by which of the two fonts have the code point, empty
+// when neither does
+func cmp_Color(r rune) string {
+	left, right := runeSupported(r), cmp_Supports(r)
+	switch {
+	case left && right:
+		return sakurapine.Paint.Tree
+	case left && !right:
+		return sakurapine.Paint.Foam
+	case right && !left:
+		return sakurapine.Paint.Gold
+	}
+	return ""
+}
+
+// Adds the comparison colour to the style of a cell that isn't selected
+func cmp_Style(r rune, style string, selected bool) string {
+	if !tbl_compare || selected {
+		return style
+	}
+	color := cmp_Color(r)
+	if color == "" {
+		return style
+	}
+	return style + "background-color: " + color + "; color: " + sakurapine.Layer.Base + ";"
+}
+
+func renderCompare() {
+	if !tbl_compare || cmp_Raw == nil {
+		return
+	}
+	if cmp_Table.RowCount() != tableWidget.RowCount() {
+		cmp_Resize()
+	}
+
+	rows := tableWidget.RowCount()
+	sheetN := tableScroller.Value() - (rows / 3)
+	curR, curC := tableWidget.CurrentRow(), tableWidget.CurrentColumn()
+	for idx := rows / 3; idx <= rows/3*2 && idx < rows; idx++ {
+		cmp_Table.SetRowHeight(idx, tableWidget.RowHeight(idx))
+		for col := range 16 {
+			var label *qt6.QLabel
+			if cell := cmp_Table.CellWidget(idx, col); cell != nil {
+				label = qt6.UnsafeNewQLabel(cell.Metacast("QLabel"))
+			} else {
+				label = qt6.NewQLabel2()
+				label.SetAlignment(qt6.AlignCenter)
+				cmp_Table.SetCellWidget(idx, col, label.QWidget)
+			}
+
+			char, ok := cellRune(sheetN+idx, col)
+			if !ok {
+				label.SetText("")
+				label.SetStyleSheet("")
+				continue
+			}
+
+			selected := idx == curR && col == curC
+			text, font, style := string(char), cmp_Real, ""
+			if !cmp_Supports(char) {
+				text, font = runeFallback(char), monoFont
+				style = "background-color: " + sakurapine.Hl.Low + "; color: " + sakurapine.Text.Muted + ";"
+			}
+			if selected {
+				style = "color: " + sakurapine.Layer.Base + "; font-weight: bold;"
+			}
+			style = cmp_Style(char, style, selected)
+
+			label.SetText(text)
+			if label.Font().Key() != font.Key() {
+				label.SetFont(font)
+			}
+			if label.StyleSheet() != style {
+				label.SetStyleSheet(style)
+			}
+		}
+	}
+
+	cmp_ignoreCell = true
+	cmp_Table.SetCurrentCell(curR, curC)
+	cmp_ignoreCell = false
+}
+
+// Counts per block which assigned code points only one of the fonts has
+func updateCompare_Blocks() {
+	left, right := currentCmap(), cmp_Cmap
+	leftFam, rightFam := fontKey(fontPair.Raw), fontKey(cmp_Raw)
+	pair := leftFam + "\x00" + rightFam
+	if pair == cmp_Counted {
+		return
+	}
+	if cmp_Cancel != nil {
+		cmp_Cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cmp_Cancel = cancel
+	cmp_Counted = ""
+
+	cmp_Summary.SetText(fmt.Sprintf("<b>%s</b> against <b>%s</b>: counting...", leftFam, rightFam))
+	cmp_Blocks.Clear()
+	cmp_Blocks.SetHeaderLabels([]string{"Block", "Only " + leftFam, "Only " + rightFam, "Both"})
+
+	go func() {
+		blocksMut.Lock()
+		if len(blocks) == 0 {
+			blocksMut.Unlock()
+			mainthread.Wait(func() {
+				if ctx.Err() == nil {
+					cmp_Summary.SetText("Unicode blocks are still loading")
+				}
+			})
+			return
+		}
+		todo := blocks[:len(blocks)-1]
+		blocksMut.Unlock()
+
+		deltas := []CompareDelta{}
+		total := CompareDelta{}
+		for _, block := range todo {
+			if ctx.Err() != nil {
+				return
+			}
+			delta := CompareDelta{Block: block.Name, Start: block.Start}
+			for r := block.Start; r <= block.End; r++ {
+				if !runeAssigned(r) {
+					continue
+				}
+				inLeft, inRight := left[r] != 0, right[r] != 0
+				switch {
+				case inLeft && inRight:
+					delta.Both++
+				case inLeft:
+					delta.OnlyLeft++
+				case inRight:
+					delta.OnlyRight++
+				}
+			}
+			total.OnlyLeft += delta.OnlyLeft
+			total.OnlyRight += delta.OnlyRight
+			total.Both += delta.Both
+			if delta.OnlyLeft+delta.OnlyRight > 0 {
+				deltas = append(deltas, delta)
+			}
+		}
+
+		mainthread.Wait(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			cmp_Counted = pair
+			updateCompare_Tree(deltas)
+			cmp_Summary.SetText(fmt.Sprintf(
+				"<b>%s</b> against <b>%s</b>: "+
+					"<span style='background-color: %s; color: %s'>&nbsp;%d only left&nbsp;</span>, "+
+					"<span style='background-color: %s; color: %s'>&nbsp;%d only right&nbsp;</span>, "+
+					"<span style='background-color: %s; color: %s'>&nbsp;%d in both&nbsp;</span>, "+
+					"differing in %d blocks",
+				leftFam, rightFam,
+				sakurapine.Paint.Foam, sakurapine.Layer.Base, total.OnlyLeft,
+				sakurapine.Paint.Gold, sakurapine.Layer.Base, total.OnlyRight,
+				sakurapine.Paint.Tree, sakurapine.Layer.Base, total.Both,
+				len(deltas),
+			))
+		})
+	}()
+}
+
+func updateCompare_Tree(deltas []CompareDelta) {
+	cmp_Blocks.Clear()
+	leftBrush := qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Paint.Foam))
+	rightBrush := qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Paint.Gold))
+	bothBrush := qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Paint.Tree))
+	for _, delta := range deltas {
+		item := qt6.NewQTreeWidgetItem()
+		item.SetText(0, delta.Block)
+		item.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(int(delta.Start)))
+		for col, n := range []int{delta.OnlyLeft, delta.OnlyRight, delta.Both} {
+			item.SetText(col+1, fmt.Sprint(n))
+			item.SetTextAlignment2(col+1, qt6.AlignRight|qt6.AlignVCenter)
+		}
+		if delta.OnlyLeft > 0 {
+			item.SetForeground(1, leftBrush)
+		}
+		if delta.OnlyRight > 0 {
+			item.SetForeground(2, rightBrush)
+		}
+		if delta.Both > 0 {
+			item.SetForeground(3, bothBrush)
+		}
+		cmp_Blocks.AddTopLevelItem(item)
+	}
+	for col := range 4 {
+		cmp_Blocks.ResizeColumnToContents(col)
+	}
+}
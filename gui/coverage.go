@@ -0,0 +1,193 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/tables"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+var (
+	cov_Dialog    *qt6.QDialog
+	cov_Input     *qt6.QPlainTextEdit
+	cov_FileLabel *qt6.QLabel
+	cov_Summary   *qt6.QLabel
+	cov_Tab       *qt6.QTabWidget
+	cov_Blocks    *qt6.QTreeWidget
+	cov_Scripts   *qt6.QTreeWidget
+
+	cov_Corpus    tables.Corpus
+	cov_ignoreEvt = false
+)
+
+func showCoverage() {
+	if cov_Dialog == nil {
+		makeCoverage()
+	}
+	cov_Dialog.Show()
+	cov_Dialog.Raise()
+	cov_Dialog.ActivateWindow()
+}
+
+func makeCoverage() {
+	cov_Dialog = qt6.NewQDialog(window.QWidget)
+	cov_Dialog.SetWindowTitle("Document Coverage")
+	cov_Dialog.Resize(760, 680)
+	layout := qt6.NewQVBoxLayout(cov_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	btnOpen := qt6.NewQPushButton3("Open file...")
+	btnOpen.OnClicked(cov_OpenEvt)
+	btnCheck := qt6.NewQPushButton3("Check current font")
+	btnCheck.OnClicked(updateCoverage)
+	cov_FileLabel = qt6.NewQLabel3("Pasted text")
+	headLayout.AddWidget(btnOpen.QWidget)
+	headLayout.AddWidget2(cov_FileLabel.QWidget, 1)
+	headLayout.AddWidget(btnCheck.QWidget)
+
+	cov_Input = qt6.NewQPlainTextEdit2()
+	cov_Input.SetPlaceholderText("Paste a corpus here, or open a text, Markdown, HTML or PO file")
+	cov_Input.OnTextChanged(func() {
+		if cov_ignoreEvt {
+			cov_ignoreEvt = false
+			return
+		}
+		cov_FileLabel.SetText("Pasted text")
+	})
+
+	cov_Summary = qt6.NewQLabel2()
+	cov_Summary.SetWordWrap(true)
+
+	cov_Blocks = makeCoverage_Tree()
+	cov_Scripts = makeCoverage_Tree()
+	cov_Tab = qt6.NewQTabWidget2()
+	cov_Tab.AddTab(cov_Blocks.QWidget, "Missing by Block")
+	cov_Tab.AddTab(cov_Scripts.QWidget, "Missing by Script")
+	cov_Tab.AddTab(makeRank(), "Installed Fonts")
+	cov_Tab.SetDocumentMode(true)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(cov_Input.QWidget, 1)
+	layout.AddWidget(cov_Summary.QWidget)
+	layout.AddWidget2(cov_Tab.QWidget, 2)
+}
+
+func makeCoverage_Tree() *qt6.QTreeWidget {
+	tree := qt6.NewQTreeWidget2()
+	tree.SetColumnCount(4)
+	tree.SetHeaderLabels([]string{"Group", "Missing", "Name", "Context"})
+	tree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		if item.Parent() == nil {
+			return
+		}
+
+		code := strings.Fields(item.Text(0))
+		point, err := strconv.ParseInt(strings.TrimPrefix(code[0], "U+"), 16, 64)
+		if err != nil {
+			return
+		}
+		onLink(fmt.Sprint(point))
+	})
+	return tree
+}
+
+func cov_OpenEvt() {
+	path := qt6.QFileDialog_GetOpenFileName4(
+		cov_Dialog.QWidget, "Open corpus", "",
+		"Documents (*.txt *.md *.markdown *.html *.htm *.xhtml *.po *.pot);;All files (*)",
+	)
+	if path == "" {
+		return
+	}
+
+	text, err := tables.ReadCorpus(path)
+	if err != nil {
+		cov_FileLabel.SetText(err.Error())
+		return
+	}
+
+	cov_FileLabel.SetText(filepath.Base(path))
+	cov_ignoreEvt = true
+	cov_Input.SetPlainText(text)
+	updateCoverage()
+}
+
+func updateCoverage() {
+	cov_Corpus = tables.NewCorpus(cov_Input.ToPlainText())
+
+	missing := []rune{}
+	for _, r := range cov_Corpus.Points {
+		if !runeSupported(r) {
+			missing = append(missing, r)
+		}
+	}
+
+	total := len(cov_Corpus.Points)
+	covered := 100.0
+	if total > 0 {
+		covered = 100 * float64(total-len(missing)) / float64(total)
+	}
+	cov_Summary.SetText(fmt.Sprintf(
+		"<b>%s</b>: %d distinct code points, %d missing (%.1f%% covered)",
+		fontPair.Raw.FamilyName(), total, len(missing), covered,
+	))
+
+	updateCoverage_Tree(cov_Blocks, missing, func(r rune) string {
+		return runeBlock(r).Name
+	})
+	updateCoverage_Tree(cov_Scripts, missing, tables.ScriptOf)
+}
+
+func updateCoverage_Tree(tree *qt6.QTreeWidget, missing []rune, groupOf func(rune) string) {
+	tree.Clear()
+
+	totals := map[string]int{}
+	for _, r := range cov_Corpus.Points {
+		totals[groupOf(r)]++
+	}
+
+	groups := map[string][]rune{}
+	for _, r := range missing {
+		group := groupOf(r)
+		groups[group] = append(groups[group], r)
+	}
+
+	keys := []string{}
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if len(groups[a]) != len(groups[b]) {
+			return len(groups[b]) - len(groups[a])
+		}
+		return strings.Compare(a, b)
+	})
+
+	for _, key := range keys {
+		parent := qt6.NewQTreeWidgetItem()
+		parent.SetText(0, key)
+		parent.SetText(1, fmt.Sprintf("%d / %d", len(groups[key]), totals[key]))
+
+		for _, r := range groups[key] {
+			child := qt6.NewQTreeWidgetItem()
+			child.SetText(0, fmt.Sprintf("U+%04X", r))
+			child.SetText(1, fmt.Sprintf("×%d", cov_Corpus.Counts[r]))
+			child.SetText(2, runeName(r))
+			child.SetText(3, cov_Corpus.Contexts[r])
+			child.SetToolTip(3, cov_Corpus.Contexts[r])
+			parent.AddChild(child)
+		}
+		tree.AddTopLevelItem(parent)
+	}
+
+	for col := range 3 {
+		tree.ResizeColumnToContents(col)
+	}
+}
@@ -0,0 +1,485 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+// A code point whose glyph differs between the two builds, or for glyphs no
+// code point reaches, like ligatures and alternates, a Point of -1 and the
+// glyph name both builds share
+type DiffGlyph struct {
+	Point    rune
+	Name     string
+	Old, New uint16
+	Change   string
+}
+
+// One build of a font, loaded without registering it with Qt
+type DiffSide struct {
+	Path string
+	Sfnt *sfnt.Font
+	Data []byte
+}
+
+var (
+	diff_Dialog  *qt6.QDialog
+	diff_Labels  [2]*qt6.QLabel
+	diff_Summary *qt6.QLabel
+	diff_Chars   *qt6.QTreeWidget
+	diff_Glyphs  *qt6.QTreeWidget
+	diff_Metrics *qt6.QTreeWidget
+	diff_Names   *qt6.QTreeWidget
+	diff_Preview *qt6.QLabel
+	diff_Legend  *qt6.QLabel
+
+	diff_Sides   [2]*DiffSide
+	diff_Raw     [2]*qt6.QRawFont
+	diff_Changed []DiffGlyph
+	diff_Gen     atomic.Int64
+)
+
+func showDiff() {
+	if diff_Dialog == nil {
+		makeDiff()
+	}
+	diff_Dialog.Show()
+	diff_Dialog.Raise()
+	diff_Dialog.ActivateWindow()
+}
+
+func makeDiff() {
+	diff_Dialog = qt6.NewQDialog(window.QWidget)
+	diff_Dialog.SetWindowTitle("Compare Font Versions")
+	diff_Dialog.Resize(900, 640)
+	layout := qt6.NewQVBoxLayout(diff_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQGridLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+	for side, title := range []string{"Old build...", "New build..."} {
+		btn := qt6.NewQPushButton3(title)
+		btn.OnClicked(func() { diff_OpenEvt(side) })
+		diff_Labels[side] = qt6.NewQLabel3("No file")
+		headLayout.AddWidget2(btn.QWidget, side, 0)
+		headLayout.AddWidget2(diff_Labels[side].QWidget, side, 1)
+	}
+	headLayout.SetColumnStretch(1, 1)
+
+	diff_Summary = qt6.NewQLabel3("Open the old and the new build of a font")
+	diff_Summary.SetWordWrap(true)
+
+	diff_Chars = makeDiff_Tree("Code Point", "Change", "Name")
+	diff_Chars.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		if item.Parent() != nil {
+			onLink(fmt.Sprint(item.Data(0, int(qt6.UserRole)).ToInt()))
+		}
+	})
+	diff_Glyphs = makeDiff_Tree("Glyph", "Change", "Name")
+	diff_Glyphs.SetRootIsDecorated(false)
+	diff_Glyphs.OnCurrentItemChanged(func(item, _ *qt6.QTreeWidgetItem) {
+		if item != nil {
+			updateDiff_Preview(diff_Changed[item.Data(0, int(qt6.UserRole)).ToInt()])
+		}
+	})
+	diff_Metrics = makeDiff_Tree("Table", "Field", "Old", "New")
+	diff_Metrics.SetRootIsDecorated(false)
+	diff_Names = makeDiff_Tree("Table", "Field", "Old", "New")
+	diff_Names.SetRootIsDecorated(false)
+
+	tab := qt6.NewQTabWidget2()
+	tab.AddTab(diff_Chars.QWidget, "Characters")
+	tab.AddTab(diff_Glyphs.QWidget, "Glyphs")
+	tab.AddTab(diff_Metrics.QWidget, "Metrics")
+	tab.AddTab(diff_Names.QWidget, "Names")
+	tab.SetDocumentMode(true)
+
+	previewWidget := qt6.NewQWidget2()
+	previewLayout := qt6.NewQVBoxLayout(previewWidget)
+	previewLayout.SetContentsMargins(0, 0, 0, 0)
+	diff_Preview = qt6.NewQLabel2()
+	diff_Preview.SetAlignment(qt6.AlignCenter)
+	diff_Preview.SetFixedSize2(288, 288)
+	diff_Legend = qt6.NewQLabel2()
+	diff_Legend.SetWordWrap(true)
+	diff_Legend.SetText(fmt.Sprintf(
+		"<span style='color: %s'>■</span> old build, <span style='color: %s'>■</span> new build",
+		sakurapine.Paint.Love, sakurapine.Paint.Foam,
+	))
+	previewLayout.AddWidget(diff_Preview.QWidget)
+	previewLayout.AddWidget(diff_Legend.QWidget)
+	previewLayout.AddStretch()
+
+	bodyWidget := qt6.NewQWidget2()
+	bodyLayout := qt6.NewQHBoxLayout(bodyWidget)
+	bodyLayout.SetContentsMargins(0, 0, 0, 0)
+	bodyLayout.AddWidget2(tab.QWidget, 1)
+	bodyLayout.AddWidget(previewWidget)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget(diff_Summary.QWidget)
+	layout.AddWidget2(bodyWidget, 1)
+}
+
+func makeDiff_Tree(labels ...string) *qt6.QTreeWidget {
+	tree := qt6.NewQTreeWidget2()
+	tree.SetColumnCount(len(labels))
+	tree.SetHeaderLabels(labels)
+	return tree
+}
+
+func diff_OpenEvt(side int) {
+	path := qt6.QFileDialog_GetOpenFileName4(
+		diff_Dialog.QWidget, []string{"Open old build", "Open new build"}[side], "", fontFile_Filter,
+	)
+	if path == "" {
+		return
+	}
+
+	loaded, err := diff_Load(path)
+	if err != nil {
+		qt6.QMessageBox_Warning(diff_Dialog.QWidget, "Compare font versions", err.Error())
+		return
+	}
+	if loaded == nil {
+		return
+	}
+	diff_Sides[side] = loaded
+	diff_Labels[side].SetText(fmt.Sprintf("%s, version %s", filepath.Base(path), loaded.Sfnt.Name(5)))
+	diff_Labels[side].SetToolTip(path)
+	updateDiff()
+}
+
+// Nil without an error if the user cancelled picking a face
+func diff_Load(path string) (*DiffSide, error) {
+	data, idx, _, _, err := readFontFile(path)
+	if err != nil || idx < 0 {
+		return nil, err
+	}
+	face, err := sfnt.ParseFace(data, idx)
+	if err != nil {
+		return nil, err
+	}
+	// Qt only loads the first face of collection data
+	data, err = face.Standalone()
+	if err != nil {
+		return nil, err
+	}
+	return &DiffSide{path, face, data}, nil
+}
+
+func updateDiff() {
+	for _, tree := range []*qt6.QTreeWidget{diff_Chars, diff_Glyphs, diff_Metrics, diff_Names} {
+		tree.Clear()
+	}
+	diff_Preview.Clear()
+	diff_Changed = nil
+	old, cur := diff_Sides[0], diff_Sides[1]
+	if old == nil || cur == nil {
+		return
+	}
+
+	// Both at the size of the old em, so a changed em alone isn't a change
+	// to every outline
+	px := 1000.0
+	if head, err := old.Sfnt.Head(); err == nil && head.UnitsPerEm > 0 {
+		px = float64(head.UnitsPerEm)
+	}
+	diff_Raw = [2]*qt6.QRawFont{
+		qt6.NewQRawFont6(old.Data, px, qt6.QFont__PreferNoHinting),
+		qt6.NewQRawFont6(cur.Data, px, qt6.QFont__PreferNoHinting),
+	}
+	if !diff_Raw[0].IsValid() || !diff_Raw[1].IsValid() {
+		diff_Summary.SetText("Qt could not load one of the fonts")
+		return
+	}
+
+	updateDiff_Changes(diff_Metrics, sfnt.MetricChanges(old.Sfnt, cur.Sfnt))
+	updateDiff_Changes(diff_Names, sfnt.NameChanges(old.Sfnt, cur.Sfnt))
+	added, removed, err := sfnt.CmapChanges(old.Sfnt, cur.Sfnt)
+	if err != nil {
+		diff_Summary.SetText(err.Error())
+		return
+	}
+	updateDiff_Chars(added, removed)
+
+	gen := diff_Gen.Add(1)
+	diff_Summary.SetText(fmt.Sprintf(
+		"%d characters added, %d removed, comparing glyphs...", len(added), len(removed),
+	))
+	raws := diff_Raw
+	go func() {
+		changed := diffGlyphs(old.Sfnt, cur.Sfnt, raws, gen)
+		mainthread.Wait(func() {
+			if diff_Gen.Load() != gen {
+				return
+			}
+			diff_Changed = changed
+			updateDiff_Glyphs()
+			diff_Summary.SetText(fmt.Sprintf(
+				"%d characters added, %d removed, %d changed glyphs, "+
+					"%d metrics and %d names changed",
+				len(added), len(removed), len(changed),
+				diff_Metrics.TopLevelItemCount(), diff_Names.TopLevelItemCount(),
+			))
+		})
+	}()
+}
+
+// Compares the glyphs of every code point both builds map, then the glyphs
+// left over that share a post or CFF name, by outline and advance width.
+// Glyph IDs may be renumbered between builds. Runs in the
+// background, handing the outline comparisons to the main thread that owns
+// the QRawFonts a batch at a time, and stops once `gen` is stale.
+func diffGlyphs(old, cur *sfnt.Font, raws [2]*qt6.QRawFont, gen int64) []DiffGlyph {
+	oldCmap, err := old.Cmap()
+	if err != nil {
+		return nil
+	}
+	curCmap, err := cur.Cmap()
+	if err != nil {
+		return nil
+	}
+	points := []rune{}
+	for r := range oldCmap {
+		if _, ok := curCmap[r]; ok {
+			points = append(points, r)
+		}
+	}
+	slices.Sort(points)
+
+	// Several code points often share a glyph
+	pairs := [][2]uint16{}
+	seen := map[[2]uint16]bool{}
+	oldSeen, curSeen := map[uint16]bool{}, map[uint16]bool{}
+	for _, r := range points {
+		pair := [2]uint16{oldCmap[r], curCmap[r]}
+		if !seen[pair] {
+			seen[pair] = true
+			oldSeen[pair[0]], curSeen[pair[1]] = true, true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	// Names repeated within a build can't be matched
+	oldNames, curNames := diffNameIndex(old), diffNameIndex(cur)
+	named := [][2]uint16{}
+	names := map[[2]uint16]string{}
+	for gid, name := range old.GlyphNames() {
+		curGid, ok := curNames[name]
+		if oldNames[name] != gid || !ok || curGid < 0 || oldSeen[uint16(gid)] || curSeen[uint16(curGid)] {
+			continue
+		}
+		pair := [2]uint16{uint16(gid), uint16(curGid)}
+		names[pair] = name
+		named = append(named, pair)
+	}
+	pairs = append(pairs, named...)
+
+	verdicts := diffVerdicts(old, cur, raws, pairs, gen)
+	if verdicts == nil {
+		return nil
+	}
+
+	ret := []DiffGlyph{}
+	for _, r := range points {
+		pair := [2]uint16{oldCmap[r], curCmap[r]}
+		if change := verdicts[pair]; change != "" {
+			ret = append(ret, DiffGlyph{r, "", pair[0], pair[1], change})
+		}
+	}
+	for _, pair := range named {
+		if change := verdicts[pair]; change != "" {
+			ret = append(ret, DiffGlyph{-1, names[pair], pair[0], pair[1], change})
+		}
+	}
+	return ret
+}
+
+// Glyph ID by name, -1 for a name several glyphs share
+func diffNameIndex(font *sfnt.Font) map[string]int {
+	ret := map[string]int{}
+	for gid, name := range font.GlyphNames() {
+		if _, ok := ret[name]; ok {
+			ret[name] = -1
+		} else if name != "" {
+			ret[name] = gid
+		}
+	}
+	return ret
+}
+
+// What changed in each old and new glyph pair, nil once `gen` is stale
+func diffVerdicts(old, cur *sfnt.Font, raws [2]*qt6.QRawFont, pairs [][2]uint16, gen int64) map[[2]uint16]string {
+	const batch = 256
+	ratio := diffEmRatio(old, cur)
+	ret := map[[2]uint16]string{}
+	for start := 0; start < len(pairs); start += batch {
+		if diff_Gen.Load() != gen {
+			return nil
+		}
+		chunk := pairs[start:min(start+batch, len(pairs))]
+		same := mainthread.Wait2(func() []bool {
+			ret := make([]bool, len(chunk))
+			for idx, pair := range chunk {
+				ret[idx] = diffSamePath(raws[0].PathForGlyph(uint(pair[0])), raws[1].PathForGlyph(uint(pair[1])))
+			}
+			return ret
+		})
+
+		for idx, pair := range chunk {
+			changes := []string{}
+			if !same[idx] {
+				changes = append(changes, "outline")
+			}
+			oldAdvance, _ := old.HMetrics(pair[0])
+			curAdvance, _ := cur.HMetrics(pair[1])
+			if math.Abs(float64(oldAdvance)-float64(curAdvance)*ratio) >= 0.5 {
+				changes = append(changes, fmt.Sprintf("advance %d → %d", oldAdvance, curAdvance))
+			}
+			ret[pair] = strings.Join(changes, ", ")
+		}
+	}
+	return ret
+}
+
+// Scales the new build's units to the old em, like the outlines are
+func diffEmRatio(old, cur *sfnt.Font) float64 {
+	oldHead, err := old.Head()
+	if err != nil || oldHead.UnitsPerEm == 0 {
+		return 1
+	}
+	curHead, err := cur.Head()
+	if err != nil || curHead.UnitsPerEm == 0 {
+		return 1
+	}
+	return float64(oldHead.UnitsPerEm) / float64(curHead.UnitsPerEm)
+}
+
+// Paths are compared in the old font's units, rounding off what scaling to
+// a different em introduces
+func diffSamePath(a, b *qt6.QPainterPath) bool {
+	if a.ElementCount() != b.ElementCount() {
+		return false
+	}
+	for idx := range a.ElementCount() {
+		ea, eb := a.ElementAt(idx), b.ElementAt(idx)
+		if ea.IsMoveTo() != eb.IsMoveTo() || ea.IsLineTo() != eb.IsLineTo() || ea.IsCurveTo() != eb.IsCurveTo() {
+			return false
+		}
+		pa, pb := ea.ToQPointF(), eb.ToQPointF()
+		if math.Abs(pa.X()-pb.X()) > 0.01 || math.Abs(pa.Y()-pb.Y()) > 0.01 {
+			return false
+		}
+	}
+	return true
+}
+
+func updateDiff_Chars(added, removed []rune) {
+	for _, group := range []struct {
+		title  string
+		points []rune
+	}{{"Added", added}, {"Removed", removed}} {
+		parent := qt6.NewQTreeWidgetItem()
+		parent.SetText(0, group.title)
+		parent.SetText(1, fmt.Sprint(len(group.points)))
+		for _, r := range group.points {
+			child := qt6.NewQTreeWidgetItem()
+			child.SetText(0, fmt.Sprintf("U+%04X %s", r, string(r)))
+			child.SetText(1, group.title)
+			child.SetText(2, runeName(r))
+			child.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(int(r)))
+			parent.AddChild(child)
+		}
+		diff_Chars.AddTopLevelItem(parent)
+	}
+	diff_Chars.ResizeColumnToContents(0)
+}
+
+func updateDiff_Glyphs() {
+	for idx, glyph := range diff_Changed {
+		item := qt6.NewQTreeWidgetItem()
+		if glyph.Point < 0 {
+			item.SetText(0, glyph.Name)
+			item.SetText(2, fmt.Sprintf("unencoded, glyph %d → %d", glyph.Old, glyph.New))
+		} else {
+			item.SetText(0, fmt.Sprintf("U+%04X %s", glyph.Point, string(glyph.Point)))
+			item.SetText(2, runeName(glyph.Point))
+		}
+		item.SetText(1, glyph.Change)
+		item.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(idx))
+		diff_Glyphs.AddTopLevelItem(item)
+	}
+	diff_Glyphs.ResizeColumnToContents(0)
+	diff_Glyphs.ResizeColumnToContents(1)
+}
+
+func updateDiff_Changes(tree *qt6.QTreeWidget, changes []sfnt.Change) {
+	for _, change := range changes {
+		item := qt6.NewQTreeWidgetItem()
+		for col, text := range []string{change.Table, change.Field, change.Old, change.New} {
+			item.SetText(col, text)
+			item.SetToolTip(col, text)
+		}
+		tree.AddTopLevelItem(item)
+	}
+	for col := range 3 {
+		tree.ResizeColumnToContents(col)
+	}
+}
+
+// Draws the old and the new glyph over each other on a shared baseline
+func updateDiff_Preview(glyph DiffGlyph) {
+	size := diff_Preview.Width()
+	pix := qt6.NewQPixmap2(size, size)
+	pix.FillWithFillColor(qt6.NewQColor2(qt6.Transparent))
+
+	old, cur := diff_Raw[0], diff_Raw[1]
+	scale := glyphScale(old, size)
+	if scale == 0 {
+		return
+	}
+	advance := max(
+		old.AdvancesForGlyphIndexes([]uint{uint(glyph.Old)})[0].X(),
+		cur.AdvancesForGlyphIndexes([]uint{uint(glyph.New)})[0].X(),
+	)
+	height := old.Ascent() + old.Descent()
+
+	painter := qt6.NewQPainter2(pix.QPaintDevice)
+	painter.SetRenderHint(qt6.QPainter__Antialiasing)
+	painter.Translate2(
+		(float64(size)-advance*scale)/2,
+		(float64(size)-height*scale)/2+old.Ascent()*scale,
+	)
+	painter.SetPenWithPen(qt6.NewQPen6(qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Text.Muted)), 1, qt6.DashLine))
+	painter.DrawLine(qt6.NewQLineF3(-float64(size), 0, float64(size), 0))
+
+	painter.Scale(scale, scale)
+	for _, layer := range []struct {
+		raw   *qt6.QRawFont
+		gid   uint16
+		color string
+		style qt6.PenStyle
+	}{
+		{old, glyph.Old, sakurapine.Paint.Love, qt6.DashLine},
+		{cur, glyph.New, sakurapine.Paint.Foam, qt6.SolidLine},
+	} {
+		path := layer.raw.PathForGlyph(uint(layer.gid))
+		fill := qt6.NewQColor6(layer.color)
+		fill.SetAlphaF(0.35)
+		painter.FillPath(path, qt6.NewQBrush3(fill))
+		pen := qt6.NewQPen6(qt6.NewQBrush3(qt6.NewQColor6(layer.color)), 1, layer.style)
+		pen.SetCosmetic(true)
+		painter.StrokePath(path, pen)
+	}
+	painter.End()
+	diff_Preview.SetPixmap(pix)
+}
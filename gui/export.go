@@ -0,0 +1,363 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+type ExportOptions struct {
+	// "svg" or "png"
+	Format string
+	// Height in pixels. An SVG of size 0 stays in font units.
+	Size int
+	// Pixels around the line box, font units for unscaled SVG
+	Padding    int
+	Foreground string
+	// Empty for a transparent background
+	Background string
+}
+
+// The layout of an exported glyph in font units, y going down like the
+// path. The box spans the advance and the ascent and descent, grown to fit
+// glyphs that overshoot them.
+type exportBox struct {
+	path                     *qt6.QPainterPath
+	left, top, width, height float64
+	scale, pad               float64
+}
+
+func exportLayout(raw *qt6.QRawFont, gid uint, opt ExportOptions) exportBox {
+	units := qt6.NewQRawFont4(raw)
+	units.SetPixelSize(units.UnitsPerEm())
+
+	box := exportBox{path: units.PathForGlyph(gid)}
+	advance := units.AdvancesForGlyphIndexes([]uint{gid})[0].X()
+	left, right := 0.0, advance
+	top, bottom := -units.Ascent(), units.Descent()
+	if !box.path.IsEmpty() {
+		rect := box.path.BoundingRect()
+		left, right = min(left, rect.Left()), max(right, rect.Right())
+		top, bottom = min(top, rect.Top()), max(bottom, rect.Bottom())
+	}
+	box.left, box.top = left, top
+	box.width, box.height = max(right-left, 1), max(bottom-top, 1)
+
+	box.scale, box.pad = 1, float64(opt.Padding)
+	if opt.Size > 0 {
+		box.scale = max(float64(opt.Size-2*opt.Padding), 1) / box.height
+		box.pad = float64(opt.Padding) / box.scale
+	}
+	return box
+}
+
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// SVG path data of a QPainterPath, cubic curves take three elements
+func svgPathData(path *qt6.QPainterPath) string {
+	sb := strings.Builder{}
+	for idx := 0; idx < path.ElementCount(); idx++ {
+		el := path.ElementAt(idx)
+		pt := el.ToQPointF()
+		switch {
+		case el.IsMoveTo():
+			if idx > 0 {
+				sb.WriteString("Z")
+			}
+			sb.WriteString("M" + svgNumber(pt.X()) + " " + svgNumber(pt.Y()))
+		case el.IsLineTo():
+			sb.WriteString("L" + svgNumber(pt.X()) + " " + svgNumber(pt.Y()))
+		case el.IsCurveTo() && idx+2 < path.ElementCount():
+			c2, end := path.ElementAt(idx+1).ToQPointF(), path.ElementAt(idx+2).ToQPointF()
+			sb.WriteString(fmt.Sprintf("C%s %s %s %s %s %s",
+				svgNumber(pt.X()), svgNumber(pt.Y()),
+				svgNumber(c2.X()), svgNumber(c2.Y()),
+				svgNumber(end.X()), svgNumber(end.Y()),
+			))
+			idx += 2
+		}
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("Z")
+	}
+	return sb.String()
+}
+
+// Fill attributes for a colour, SVG 1.1 has no alpha in colours
+func svgFill(attr, color string) string {
+	c := qt6.NewQColor6(color)
+	ret := fmt.Sprintf(`%s="%s"`, attr, c.NameWithFormat(qt6.QColor__HexRgb))
+	if c.AlphaF() < 1 {
+		ret += fmt.Sprintf(` %s-opacity="%s"`, attr, svgNumber(float64(c.AlphaF())))
+	}
+	return ret
+}
+
+func glyphSVG(raw *qt6.QRawFont, gid uint, opt ExportOptions) []byte {
+	box := exportLayout(raw, gid, opt)
+	x, y := box.left-box.pad, box.top-box.pad
+	w, h := box.width+2*box.pad, box.height+2*box.pad
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%s %s %s %s">`+"\n",
+		svgNumber(w*box.scale), svgNumber(h*box.scale), svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h),
+	))
+	if opt.Background != "" {
+		sb.WriteString(fmt.Sprintf(
+			`<rect x="%s" y="%s" width="%s" height="%s" %s/>`+"\n",
+			svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), svgFill("fill", opt.Background),
+		))
+	}
+	sb.WriteString(fmt.Sprintf(`<path %s d="%s"/>`+"\n", svgFill("fill", opt.Foreground), svgPathData(box.path)))
+	sb.WriteString("</svg>\n")
+	return []byte(sb.String())
+}
+
+func glyphPNG(raw *qt6.QRawFont, gid uint, opt ExportOptions) *qt6.QImage {
+	if opt.Size <= 0 {
+		opt.Size = int(raw.UnitsPerEm())
+	}
+	box := exportLayout(raw, gid, opt)
+	w := int(math.Ceil((box.width + 2*box.pad) * box.scale))
+	img := qt6.NewQImage3(max(w, 1), opt.Size, qt6.QImage__Format_ARGB32_Premultiplied)
+	img.Fill2(qt6.Transparent)
+	if opt.Background != "" {
+		img.FillWithColor(qt6.NewQColor6(opt.Background))
+	}
+
+	painter := qt6.NewQPainter2(img.QPaintDevice)
+	painter.SetRenderHint(qt6.QPainter__Antialiasing)
+	painter.Translate2(float64(opt.Padding)-box.left*box.scale, float64(opt.Padding)-box.top*box.scale)
+	painter.Scale(box.scale, box.scale)
+	painter.FillPath(box.path, qt6.NewQBrush3(qt6.NewQColor6(opt.Foreground)))
+	painter.End()
+	return img
+}
+
+// Writes one glyph to `path`
+func exportGlyph(raw *qt6.QRawFont, r rune, path string, opt ExportOptions) error {
+	gids := raw.GlyphIndexesForString(string(r))
+	if len(gids) == 0 || gids[0] == 0 {
+		return fmt.Errorf("U+%04X is not in %s", r, fontKey(raw))
+	}
+
+	if opt.Format == "png" {
+		if !glyphPNG(raw, gids[0], opt).Save2(path, "PNG") {
+			return fmt.Errorf("could not write %s", path)
+		}
+		return nil
+	}
+	return os.WriteFile(path, glyphSVG(raw, gids[0], opt), 0o644)
+}
+
+// Writes every glyph to `dir` as U+XXXX.svg or .png, carrying on past
+// missing glyphs
+func exportGlyphs(raw *qt6.QRawFont, runes []rune, dir string, opt ExportOptions) error {
+	errs := []error{}
+	for _, r := range runes {
+		path := filepath.Join(dir, fmt.Sprintf("U+%04X.%s", r, opt.Format))
+		if err := exportGlyph(raw, r, path, opt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Exports glyphs of an installed font without showing a window, for the
+// command line. `chars` is parsed like the export dialog's field.
+func ExportFiles(family, style, chars, dir string, opt ExportOptions) error {
+	if os.Getenv("QT_QPA_PLATFORM") == "" {
+		os.Setenv("QT_QPA_PLATFORM", "offscreen")
+	}
+	qt6.NewQGuiApplication(os.Args[:1])
+
+	if !qt6.QFontDatabase_HasFamily(family) {
+		return fmt.Errorf("no installed family %q", family)
+	}
+	font := qt6.NewQFont2(family)
+	if style != "" {
+		font = qt6.QFontDatabase_Font(family, style, 12)
+	}
+	font.SetStyleStrategy(qt6.QFont__NoFontMerging)
+	return exportGlyphs(qt6.QRawFont_FromFont(font), parseCharset(chars), dir, opt)
+}
+
+var (
+	export_Dialog      *qt6.QDialog
+	export_Summary     *qt6.QLabel
+	export_Chars       *qt6.QLineEdit
+	export_Format      *qt6.QComboBox
+	export_Size        *qt6.QSpinBox
+	export_Padding     *qt6.QSpinBox
+	export_Fg          *qt6.QPushButton
+	export_Bg          *qt6.QPushButton
+	export_Transparent *qt6.QCheckBox
+	export_Preview     *qt6.QLabel
+
+	export_FgColor = "#000000"
+	export_BgColor = "#ffffff"
+)
+
+func showExport(chars string) {
+	if export_Dialog == nil {
+		makeExport()
+	}
+	export_Chars.SetText(chars)
+	export_Dialog.Show()
+	export_Dialog.Raise()
+	export_Dialog.ActivateWindow()
+	updateExport_Preview()
+}
+
+func makeExport() {
+	export_Dialog = qt6.NewQDialog(window.QWidget)
+	export_Dialog.SetWindowTitle("Export Glyphs")
+	layout := qt6.NewQVBoxLayout(export_Dialog.QWidget)
+
+	formWidget := qt6.NewQWidget2()
+	form := qt6.NewQFormLayout(formWidget)
+	form.SetContentsMargins(0, 0, 0, 0)
+
+	export_Chars = qt6.NewQLineEdit2()
+	export_Chars.SetToolTip("Characters to export, ranges like A-Z or U+F000-U+F0FF allowed")
+	export_Chars.OnTextChanged(func(_ string) { updateExport_Preview() })
+	export_Format = qt6.NewQComboBox2()
+	export_Format.AddItems([]string{"SVG", "PNG"})
+	export_Format.OnCurrentIndexChanged(func(_ int) { updateExport_Preview() })
+	export_Size = qt6.NewQSpinBox2()
+	export_Size.SetRange(0, 8192)
+	export_Size.SetValue(256)
+	export_Size.SetSuffix(" px")
+	export_Size.SetSpecialValueText("Font units")
+	export_Size.SetToolTip("Height of the image, font units keeps SVG unscaled")
+	export_Size.OnValueChanged(func(_ int) { updateExport_Preview() })
+	export_Padding = qt6.NewQSpinBox2()
+	export_Padding.SetRange(0, 4096)
+	export_Padding.SetValue(8)
+	export_Padding.SetToolTip("Pixels around the glyph, font units for unscaled SVG")
+	export_Padding.OnValueChanged(func(_ int) { updateExport_Preview() })
+
+	pickColor := func(btn *qt6.QPushButton, color *string) {
+		btn.SetStyleSheet("background-color: " + *color + ";")
+		btn.OnClicked(func() {
+			c := qt6.QColorDialog_GetColor4(
+				qt6.NewQColor6(*color), export_Dialog.QWidget, "Colour", qt6.QColorDialog__ShowAlphaChannel,
+			)
+			if !c.IsValid() {
+				return
+			}
+			*color = c.NameWithFormat(qt6.QColor__HexArgb)
+			btn.SetStyleSheet("background-color: " + c.NameWithFormat(qt6.QColor__HexRgb) + ";")
+			updateExport_Preview()
+		})
+	}
+	export_Fg = qt6.NewQPushButton2()
+	pickColor(export_Fg, &export_FgColor)
+	export_Bg = qt6.NewQPushButton2()
+	pickColor(export_Bg, &export_BgColor)
+	export_Transparent = qt6.NewQCheckBox3("Transparent")
+	export_Transparent.SetChecked(true)
+	export_Transparent.OnToggled(func(checked bool) {
+		export_Bg.SetDisabled(checked)
+		updateExport_Preview()
+	})
+	export_Bg.SetDisabled(true)
+	bgWidget := qt6.NewQWidget2()
+	bgLayout := qt6.NewQHBoxLayout(bgWidget)
+	bgLayout.SetContentsMargins(0, 0, 0, 0)
+	bgLayout.AddWidget2(export_Bg.QWidget, 1)
+	bgLayout.AddWidget(export_Transparent.QWidget)
+
+	form.AddRow3("Characters", export_Chars.QWidget)
+	form.AddRow3("Format", export_Format.QWidget)
+	form.AddRow3("Size", export_Size.QWidget)
+	form.AddRow3("Padding", export_Padding.QWidget)
+	form.AddRow3("Foreground", export_Fg.QWidget)
+	form.AddRow3("Background", bgWidget)
+
+	export_Preview = qt6.NewQLabel2()
+	export_Preview.SetAlignment(qt6.AlignCenter)
+	export_Preview.SetMinimumHeight(140)
+	export_Summary = qt6.NewQLabel2()
+	export_Summary.SetWordWrap(true)
+
+	btnExport := qt6.NewQPushButton3("Export...")
+	btnExport.OnClicked(runExport)
+
+	layout.AddWidget(formWidget)
+	layout.AddWidget2(export_Preview.QWidget, 1)
+	layout.AddWidget(export_Summary.QWidget)
+	layout.AddWidget(btnExport.QWidget)
+}
+
+func export_Options() ExportOptions {
+	opt := ExportOptions{
+		Format:     strings.ToLower(export_Format.CurrentText()),
+		Size:       export_Size.Value(),
+		Padding:    export_Padding.Value(),
+		Foreground: export_FgColor,
+	}
+	if !export_Transparent.IsChecked() {
+		opt.Background = export_BgColor
+	}
+	return opt
+}
+
+func updateExport_Preview() {
+	runes := parseCharset(export_Chars.Text())
+	export_Summary.SetText(fmt.Sprintf("%d glyphs from <b>%s</b>", len(runes), fontKey(fontPair.Raw)))
+	export_Preview.Clear()
+	if len(runes) == 0 {
+		return
+	}
+	gids := fontPair.Raw.GlyphIndexesForString(string(runes[0]))
+	if len(gids) == 0 || gids[0] == 0 {
+		return
+	}
+
+	opt := export_Options()
+	opt.Size = 128
+	opt.Padding = min(opt.Padding, 32)
+	export_Preview.SetPixmap(qt6.QPixmap_FromImage(glyphPNG(fontPair.Raw, gids[0], opt)))
+}
+
+func runExport() {
+	runes := parseCharset(export_Chars.Text())
+	opt := export_Options()
+	var err error
+	switch len(runes) {
+	case 0:
+		return
+	case 1:
+		path := qt6.QFileDialog_GetSaveFileName4(
+			export_Dialog.QWidget, "Export Glyph",
+			fmt.Sprintf("U+%04X.%s", runes[0], opt.Format),
+			fmt.Sprintf("%s (*.%s)", export_Format.CurrentText(), opt.Format),
+		)
+		if path == "" {
+			return
+		}
+		err = exportGlyph(fontPair.Raw, runes[0], path, opt)
+	default:
+		dir := qt6.QFileDialog_GetExistingDirectory2(export_Dialog.QWidget, "Export Glyphs")
+		if dir == "" {
+			return
+		}
+		err = exportGlyphs(fontPair.Raw, runes, dir, opt)
+	}
+
+	if err != nil {
+		export_Summary.SetText(strings.ReplaceAll(err.Error(), "\n", "<br>"))
+		return
+	}
+	export_Summary.SetText(fmt.Sprintf("Exported %d glyphs", len(runes)))
+}
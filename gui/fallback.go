@@ -0,0 +1,183 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/tables"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+type FallbackFont struct {
+	Family string
+	Style  string
+	File   string
+}
+
+var (
+	info_Fallback  GroupBox[*qt6.QTreeWidget]
+	fallbackGen    atomic.Int64
+	fallbackChains = map[string][]FallbackFont{}
+	faceMatches    = map[string]FallbackFont{}
+	fallbackMut    sync.Mutex
+	tbl_merging    = false
+)
+
+// The order fontconfig tries fonts in when `family` lacks a glyph, starting
+// with the best match for `family` itself
+func fallbackChain(family string) ([]FallbackFont, error) {
+	fallbackMut.Lock()
+	chain, ok := fallbackChains[family]
+	fallbackMut.Unlock()
+	if ok {
+		return chain, nil
+	}
+
+	_, err := exec.LookPath("fc-match")
+	if err != nil {
+		return nil, fmt.Errorf("fc-match: %s", err.Error())
+	}
+
+	out, err := exec.Command(
+		"fc-match", "-s", "-f", "%{family[0]}\t%{style[0]}\t%{file}\n", family,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("fc-match: %s", err.Error())
+	}
+
+	chain = []FallbackFont{}
+	for line := range strings.SplitSeq(string(out), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		chain = append(chain, FallbackFont{parts[0], parts[1], parts[2]})
+	}
+
+	fallbackMut.Lock()
+	fallbackChains[family] = chain
+	fallbackMut.Unlock()
+	return chain, nil
+}
+
+// The face fontconfig picks for `family` in `style`, which unlike the head
+// of the fallback chain isn't always the family's default face
+func faceMatch(family, style string) (FallbackFont, error) {
+	escape := strings.NewReplacer(`\`, `\\`, "-", `\-`, ":", `\:`, ",", `\,`)
+	pattern := escape.Replace(family) + ":style=" + escape.Replace(style)
+	fallbackMut.Lock()
+	match, ok := faceMatches[pattern]
+	fallbackMut.Unlock()
+	if ok {
+		return match, nil
+	}
+
+	_, err := exec.LookPath("fc-match")
+	if err != nil {
+		return FallbackFont{}, fmt.Errorf("fc-match: %s", err.Error())
+	}
+	out, err := exec.Command(
+		"fc-match", "-f", "%{family[0]}\t%{style[0]}\t%{file}", pattern,
+	).Output()
+	if err != nil {
+		return FallbackFont{}, fmt.Errorf("fc-match: %s", err.Error())
+	}
+	parts := strings.Split(string(out), "\t")
+	if len(parts) != 3 {
+		return FallbackFont{}, fmt.Errorf("fc-match: no match for %q", pattern)
+	}
+	match = FallbackFont{parts[0], parts[1], parts[2]}
+
+	fallbackMut.Lock()
+	faceMatches[pattern] = match
+	fallbackMut.Unlock()
+	return match, nil
+}
+
+func makeInfo_Fallback() *qt6.QWidget {
+	tree := info_Fallback.Init("Fontconfig Fallback", qt6.NewQTreeWidget2())
+	tree.SetColumnCount(3)
+	tree.SetHeaderLabels([]string{"#", "Family", "File"})
+	tree.SetRootIsDecorated(false)
+	return info_Fallback.group.QWidget
+}
+
+func updateInfo_Fallback(node tables.Node) {
+	gen := fallbackGen.Add(1)
+	family := fontPair.Raw.FamilyName()
+	tree := info_Fallback.widget
+	info_Fallback.group.SetTitle("Fontconfig Fallback (searching...)")
+
+	go func() {
+		chain, err := fallbackChain(family)
+		renderer := -1
+		for idx, font := range chain {
+			if fallbackGen.Load() != gen {
+				return
+			}
+			if familySupports(font.Family, node.Point) {
+				renderer = idx
+				break
+			}
+		}
+
+		mainthread.Wait(func() {
+			if fallbackGen.Load() != gen {
+				return
+			}
+
+			tree.Clear()
+			if err != nil {
+				info_Fallback.group.SetTitle("Fontconfig Fallback")
+				item := qt6.NewQTreeWidgetItem()
+				item.SetText(1, err.Error())
+				tree.AddTopLevelItem(item)
+				return
+			}
+
+			if renderer < 0 {
+				info_Fallback.group.SetTitle(fmt.Sprintf(
+					"Fontconfig Fallback (U+%04X is not rendered)", node.Point,
+				))
+			} else {
+				info_Fallback.group.SetTitle(fmt.Sprintf(
+					"Fontconfig Fallback (U+%04X from %s)", node.Point, chain[renderer].Family,
+				))
+			}
+
+			bold := qt6.NewQFont5(tree.Font())
+			bold.SetBold(true)
+			highlight := qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Paint.Tree))
+			for idx, font := range chain {
+				item := qt6.NewQTreeWidgetItem()
+				item.SetText(0, fmt.Sprint(idx))
+				item.SetText(1, font.Family)
+				item.SetText(2, font.File)
+				item.SetToolTip(1, font.Style)
+				if idx == renderer {
+					for col := range 3 {
+						item.SetFont(col, bold)
+						item.SetForeground(col, highlight)
+					}
+				}
+				tree.AddTopLevelItem(item)
+			}
+			tree.ResizeColumnToContents(0)
+			tree.ResizeColumnToContents(1)
+			if renderer >= 0 {
+				tree.ScrollToItem(tree.TopLevelItem(renderer))
+			}
+		})
+	}()
+}
+
+func toggleMerging(checked bool) {
+	tbl_merging = checked
+	labelCache = FontCache[Render]{}
+	selectedCache = FontCache[Render]{}
+	renderGlyphs()
+}
@@ -0,0 +1,73 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/tables"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	info_Families     GroupBox[*qt6.QTreeWidget]
+	familiesGen       atomic.Int64
+	installedFamilies []string
+)
+
+func makeInfo_Families() *qt6.QWidget {
+	tree := info_Families.Init("Installed Fonts", qt6.NewQTreeWidget2())
+	tree.SetColumnCount(2)
+	tree.SetHeaderHidden(true)
+	tree.SetRootIsDecorated(false)
+	tree.SetToolTip("Click a family to load it")
+	tree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		fontBox.SetCurrentFont(qt6.NewQFont2(item.Text(1)))
+	})
+	return info_Families.group.QWidget
+}
+
+func updateInfo_Families(node tables.Node) {
+	if installedFamilies == nil {
+		installedFamilies = qt6.QFontDatabase_Families()
+	}
+
+	gen := familiesGen.Add(1)
+	tree := info_Families.widget
+	tree.Clear()
+	info_Families.group.SetTitle("Installed Fonts (searching...)")
+
+	go func() {
+		found := []string{}
+		for _, fam := range installedFamilies {
+			if familiesGen.Load() != gen {
+				return
+			}
+			if familySupports(fam, node.Point) {
+				found = append(found, fam)
+			}
+		}
+
+		mainthread.Wait(func() {
+			if familiesGen.Load() != gen {
+				return
+			}
+
+			for _, fam := range found {
+				font := qt6.NewQFont2(fam)
+				font.SetPixelSize(24)
+				font.SetStyleStrategy(qt6.QFont__NoFontMerging)
+
+				item := qt6.NewQTreeWidgetItem()
+				item.SetText(0, string(node.Point))
+				item.SetFont(0, font)
+				item.SetText(1, fam)
+				tree.AddTopLevelItem(item)
+			}
+			tree.ResizeColumnToContents(0)
+			info_Families.group.SetTitle(fmt.Sprintf(
+				"Installed Fonts (%d of %d)", len(found), len(installedFamilies),
+			))
+		})
+	}()
+}
@@ -0,0 +1,376 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"fontview/tables"
+	"slices"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+var (
+	feat_Dialog    *qt6.QDialog
+	feat_Summary   *qt6.QLabel
+	feat_Script    *qt6.QComboBox
+	feat_Lang      *qt6.QComboBox
+	feat_Tree      GroupBox[*qt6.QTreeWidget]
+	feat_Sample    *qt6.QLineEdit
+	feat_Preview   *qt6.QLabel
+	feat_PreviewSc *qt6.QScrollArea
+
+	feat_Font      *sfnt.Font
+	feat_Raw       *qt6.QRawFont
+	feat_GSUB      *sfnt.Layout
+	feat_ignoreEvt = false
+)
+
+func showFeatures() {
+	if feat_Dialog == nil {
+		makeFeatures()
+	}
+	feat_Dialog.Show()
+	feat_Dialog.Raise()
+	feat_Dialog.ActivateWindow()
+	updateFeatures()
+}
+
+func makeFeatures() {
+	feat_Dialog = qt6.NewQDialog(window.QWidget)
+	feat_Dialog.SetWindowTitle("OpenType Features")
+	feat_Dialog.Resize(760, 680)
+	layout := qt6.NewQVBoxLayout(feat_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	feat_Summary = qt6.NewQLabel2()
+	feat_Script = qt6.NewQComboBox2()
+	feat_Script.SetToolTip("Script")
+	feat_Script.OnCurrentTextChanged(func(_ string) {
+		if feat_ignoreEvt {
+			return
+		}
+		updateFeatures_Langs()
+	})
+	feat_Lang = qt6.NewQComboBox2()
+	feat_Lang.SetToolTip("Language system")
+	feat_Lang.OnCurrentTextChanged(func(_ string) {
+		if feat_ignoreEvt {
+			return
+		}
+		updateFeatures_Tree()
+	})
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("Read the features of the current font")
+	btnRefresh.OnClicked(updateFeatures)
+
+	headLayout.AddWidget2(feat_Summary.QWidget, 1)
+	headLayout.AddWidget(feat_Script.QWidget)
+	headLayout.AddWidget(feat_Lang.QWidget)
+	headLayout.AddWidget(btnRefresh.QWidget)
+
+	tree := feat_Tree.Init("Features", qt6.NewQTreeWidget2())
+	tree.SetColumnCount(3)
+	tree.SetHeaderLabels([]string{"Feature", "Name", "Selected Glyph"})
+	tree.SetIconSize(qt6.NewQSize2(32, 32))
+	tree.OnItemChanged(func(item *qt6.QTreeWidgetItem, col int) {
+		if feat_ignoreEvt || col != 0 || item.Parent() != nil {
+			return
+		}
+		updateFeatures_Preview()
+	})
+
+	previewGroup := GroupBox[*qt6.QWidget]{}
+	previewWidget := previewGroup.Init("Preview", qt6.NewQWidget2())
+	previewLayout := qt6.NewQVBoxLayout(previewWidget)
+	feat_Sample = qt6.NewQLineEdit3("office affine -> != === 0123 fi ffl")
+	feat_Sample.SetPlaceholderText("Sample text")
+	feat_Sample.OnTextChanged(func(_ string) { updateFeatures_Preview() })
+	feat_Preview = qt6.NewQLabel2()
+	feat_PreviewSc = qt6.NewQScrollArea2()
+	feat_PreviewSc.SetWidget(feat_Preview.QWidget)
+	feat_PreviewSc.SetWidgetResizable(true)
+	feat_PreviewSc.SetMinimumHeight(112)
+	previewLayout.AddWidget(feat_Sample.QWidget)
+	previewLayout.AddWidget(feat_PreviewSc.QWidget)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(feat_Tree.group.QWidget, 1)
+	layout.AddWidget(previewGroup.group.QWidget)
+}
+
+func updateFeatures() {
+	feat_Font = fontPair.Sfnt
+	feat_Raw = fontPair.Raw
+
+	gsub, err := feat_Font.GSUB()
+	if err != nil {
+		gsub = &sfnt.Layout{}
+	}
+	feat_GSUB = gsub
+
+	feat_ignoreEvt = true
+	feat_Script.Clear()
+	for _, script := range gsub.Scripts {
+		feat_Script.AddItem(script.Tag)
+	}
+	feat_ignoreEvt = false
+
+	if err != nil {
+		feat_Summary.SetText(fmt.Sprintf("<b>%s</b>: no GSUB table", feat_Raw.FamilyName()))
+	} else {
+		feat_Summary.SetText(fmt.Sprintf(
+			"<b>%s</b>: %d scripts, %d features, %d lookups",
+			feat_Raw.FamilyName(), len(gsub.Scripts), len(gsub.Features), len(gsub.Lookups),
+		))
+	}
+
+	// Start on the script of the selected glyph when the font has it
+	scripts := []string{}
+	for _, script := range gsub.Scripts {
+		scripts = append(scripts, script.Tag)
+	}
+	for _, tag := range scriptTags(tables.ScriptOf(curNode.Point)) {
+		if slices.Contains(scripts, tag) {
+			feat_ignoreEvt = true
+			feat_Script.SetCurrentText(tag)
+			feat_ignoreEvt = false
+			break
+		}
+	}
+	updateFeatures_Langs()
+}
+
+func updateFeatures_Langs() {
+	feat_ignoreEvt = true
+	feat_Lang.Clear()
+	for _, script := range feat_GSUB.Scripts {
+		if script.Tag != feat_Script.CurrentText() {
+			continue
+		}
+		for _, lang := range script.Langs {
+			feat_Lang.AddItem(lang.Tag)
+		}
+	}
+	feat_ignoreEvt = false
+	updateFeatures_Tree()
+}
+
+// Feature indexes of the current script and language grouped by tag, a tag
+// may occur more than once
+func features_ByTag() ([]string, map[string][]int) {
+	tags := []string{}
+	byTag := map[string][]int{}
+	for _, idx := range feat_GSUB.FeaturesFor(feat_Script.CurrentText(), feat_Lang.CurrentText()) {
+		if idx >= len(feat_GSUB.Features) {
+			continue
+		}
+		tag := feat_GSUB.Features[idx].Tag
+		if _, ok := byTag[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		byTag[tag] = append(byTag[tag], idx)
+	}
+	slices.Sort(tags)
+	return tags, byTag
+}
+
+func updateFeatures_Tree() {
+	tree := feat_Tree.widget
+	feat_ignoreEvt = true
+	tree.Clear()
+
+	tags, byTag := features_ByTag()
+	for _, tag := range tags {
+		name := sfnt.FeatureName(tag)
+		if ui := feat_GSUB.Features[byTag[tag][0]].UIName; ui != 0 {
+			if label := feat_Font.Name(ui); label != "" {
+				name = label
+			}
+		}
+
+		item := qt6.NewQTreeWidgetItem()
+		item.SetText(0, tag)
+		item.SetText(1, name)
+		item.SetFont(0, monoFont)
+		item.SetFlags(item.Flags() | qt6.ItemIsUserCheckable)
+		if sfnt.DefaultFeatures[tag] && !sfnt.MaskedFeatures[tag] {
+			item.SetCheckState(0, qt6.Checked)
+		} else {
+			item.SetCheckState(0, qt6.Unchecked)
+		}
+		if sfnt.MaskedFeatures[tag] {
+			item.SetToolTip(0, "Shapers apply this to some glyphs only, by position or language. "+
+				"Checked, the preview applies it to every glyph.")
+		}
+		tree.AddTopLevelItem(item)
+	}
+
+	tree.ResizeColumnToContents(0)
+	tree.ResizeColumnToContents(1)
+	feat_ignoreEvt = false
+	updateFeatures_Glyph(curNode)
+	updateFeatures_Preview()
+}
+
+// Rereads the features once the main window moved to another font. Called
+// by UpdateRealFont, so does nothing while the dialog is closed.
+func updateFeatures_Font() {
+	if feat_Dialog == nil || !feat_Dialog.IsVisible() || fontKey(feat_Raw) == fontKey(fontPair.Raw) {
+		return
+	}
+	updateFeatures()
+}
+
+// Lists what every feature substitutes for the glyph of the selected code
+// point. Called by updateInfo, so does nothing while the dialog is closed.
+func updateFeatures_Glyph(node tables.Node) {
+	if feat_Dialog == nil || !feat_Dialog.IsVisible() || feat_GSUB == nil {
+		return
+	}
+
+	cmap, _ := feat_Font.Cmap()
+	gid, ok := cmap[node.Point]
+	names := feat_Font.GlyphNames()
+	glyphName := func(gid uint16) string {
+		if int(gid) < len(names) && names[gid] != "" {
+			return names[gid]
+		}
+		return fmt.Sprintf("#%d", gid)
+	}
+
+	tree := feat_Tree.widget
+	_, byTag := features_ByTag()
+	feat_ignoreEvt = true
+	for idx := range tree.TopLevelItemCount() {
+		item := tree.TopLevelItem(idx)
+		for item.ChildCount() > 0 {
+			item.RemoveChild(item.Child(0))
+		}
+		item.SetText(2, "")
+		if !ok {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, feat := range byTag[item.Text(0)] {
+			for _, lookup := range feat_GSUB.Features[feat].Lookups {
+				for _, subst := range feat_GSUB.Substitutions(lookup, gid) {
+					input, output := []string{}, []string{}
+					for _, g := range subst.Input {
+						input = append(input, glyphName(g))
+					}
+					for _, g := range subst.Output {
+						output = append(output, glyphName(g))
+					}
+
+					arrow := " → "
+					if subst.Type == sfnt.SubstAlternate {
+						arrow = " ⇒ "
+					}
+					text := strings.Join(input, " ") + arrow + strings.Join(output, " ")
+					if seen[text] {
+						continue
+					}
+					seen[text] = true
+
+					child := qt6.NewQTreeWidgetItem()
+					child.SetText(0, text)
+					child.SetText(2, features_Kind(subst))
+					if len(subst.Output) > 0 {
+						pix := glyphPixmap(feat_Raw, uint(subst.Output[0]), 32, sakurapine.Text.Normal)
+						child.SetIcon(0, qt6.NewQIcon2(pix))
+					}
+					item.AddChild(child)
+				}
+			}
+		}
+		if n := item.ChildCount(); n > 0 {
+			item.SetText(2, fmt.Sprintf("%d substitutions", n))
+		}
+	}
+	feat_ignoreEvt = false
+}
+
+func features_Kind(subst sfnt.Substitution) string {
+	kind := map[int]string{
+		sfnt.SubstSingle:    "Single",
+		sfnt.SubstMultiple:  "Multiple",
+		sfnt.SubstAlternate: "Alternates",
+		sfnt.SubstLigature:  "Ligature",
+		sfnt.SubstReverse:   "Reverse chaining",
+	}[subst.Type]
+	if subst.Contextual {
+		kind += ", contextual"
+	}
+	return fmt.Sprintf("%s (lookup %d)", kind, subst.Lookup)
+}
+
+// Shapes the sample text with the checked features. Qt applies features
+// through HarfBuzz with no way to toggle them, so the substitutions are
+// applied here and the glyphs drawn by ID.
+func updateFeatures_Preview() {
+	if feat_GSUB == nil {
+		return
+	}
+
+	enabled := []int{}
+	_, byTag := features_ByTag()
+	tree := feat_Tree.widget
+	for idx := range tree.TopLevelItemCount() {
+		item := tree.TopLevelItem(idx)
+		if item.CheckState(0) == qt6.Checked {
+			enabled = append(enabled, byTag[item.Text(0)]...)
+		}
+	}
+
+	cmap, _ := feat_Font.Cmap()
+	input := []uint16{}
+	for _, r := range feat_Sample.Text() {
+		input = append(input, cmap[r])
+	}
+
+	gids := []uint{}
+	for _, gid := range feat_GSUB.Apply(enabled, input) {
+		gids = append(gids, uint(gid))
+	}
+	feat_Preview.SetPixmap(glyphRunPixmap(feat_Raw, gids, nil, 96, sakurapine.Text.Normal))
+}
+
+// OpenType script tags for a Unicode script name, newest first
+func scriptTags(script string) []string {
+	switch script {
+	case "Latin":
+		return []string{"latn"}
+	case "Greek":
+		return []string{"grek"}
+	case "Cyrillic":
+		return []string{"cyrl"}
+	case "Arabic":
+		return []string{"arab"}
+	case "Hebrew":
+		return []string{"hebr"}
+	case "Han":
+		return []string{"hani"}
+	case "Hiragana", "Katakana":
+		return []string{"kana"}
+	case "Hangul":
+		return []string{"hang"}
+	case "Devanagari":
+		return []string{"dev2", "deva"}
+	case "Bengali":
+		return []string{"bng2", "beng"}
+	case "Tamil":
+		return []string{"tml2", "taml"}
+	case "Thai":
+		return []string{"thai"}
+	}
+	if len(script) >= 4 {
+		return []string{strings.ToLower(script[:4])}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"fontview/bitmap"
+	"fontview/sfnt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+// A face opened from a file rather than picked from the installed fonts
+type FontFile struct {
+	Path string
+	// Index within a collection, and how many faces it has
+	Face, Faces int
+	// What the face was registered as
+	Family, Style string
+	// Parsed from the file itself, so the metadata shows the original names
+	// even when the registered copy had to be renamed
+	Sfnt    *sfnt.Font
+	AppFont int
+	// Set for BDF, PCF and PSF fonts, which are registered as outlines
+	Bitmap *bitmap.Font
+}
+
+var (
+	// Opened faces by fontKey of the registered font
+	fontFiles       = map[string]*FontFile{}
+	fontFile_Filter = "Fonts (*.ttf *.otf *.ttc *.otc *.woff *.woff2 *.bdf *.pcf *.psf *.psfu *.gz);;All files (*)"
+)
+
+// The file the current font was opened from, if any
+func currentFontFile() *FontFile {
+	return fontFiles[fontKey(fontPair.Raw)]
+}
+
+func openFontFileEvt() {
+	path := qt6.QFileDialog_GetOpenFileName4(
+		window.QWidget, "Open font file", "", fontFile_Filter,
+	)
+	if path == "" {
+		return
+	}
+	if err := openFontFile(path); err != nil {
+		qt6.QMessageBox_Warning(window.QWidget, "Open font file", err.Error())
+	}
+}
+
+// Asks which face of a collection to open, -1 if the user cancelled
+func chooseFace(path string, data []byte, num int) int {
+	items := []string{}
+	for idx := range num {
+		label := fmt.Sprintf("Face %d", idx)
+		if face, err := sfnt.ParseFace(data, idx); err == nil {
+			label = fmt.Sprintf("%d: %s", idx, face.Name(4))
+		}
+		items = append(items, label)
+	}
+
+	ok := false
+	choice := qt6.QInputDialog_GetItem4(
+		window.QWidget, "Open font collection",
+		fmt.Sprintf("%s has %d faces", filepath.Base(path), num),
+		items, 0, false, &ok,
+	)
+	if !ok {
+		return -1
+	}
+	return slices.Index(items, choice)
+}
+
+// Reads the font file at `path` as sfnt data and asks which face of a
+// collection to use, -1 if the user cancelled. Web fonts are decoded and
+// bitmap fonts converted on the way.
+func readFontFile(path string) (data []byte, idx, num int, pixels *bitmap.Font, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	// Qt doesn't load bitmap fonts, they become one square per pixel
+	if bitmap.Detect(data) {
+		name := strings.TrimSuffix(filepath.Base(path), ".gz")
+		pixels, err = bitmap.Parse(data, strings.TrimSuffix(name, filepath.Ext(name)))
+		if err != nil {
+			return nil, 0, 0, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		data = pixels.Sfnt()
+	}
+	// FreeType only reads WOFF2 when built with Brotli, so web fonts are
+	// always decoded here
+	data, err = sfnt.Unwrap(data)
+	if err != nil {
+		return nil, 0, 0, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	num = sfnt.NumFaces(data)
+	if num == 0 {
+		return nil, 0, 0, nil, fmt.Errorf("%s is not a TrueType, OpenType, WOFF or bitmap font", filepath.Base(path))
+	}
+	if num > 1 {
+		idx = chooseFace(path, data, num)
+	}
+	return data, idx, num, pixels, nil
+}
+
+// Registers one face of the font file at `path` with Qt and selects it
+func openFontFile(path string) error {
+	data, idx, num, pixels, err := readFontFile(path)
+	if err != nil || idx < 0 {
+		return err
+	}
+
+	for _, file := range fontFiles {
+		if file.Path == path && file.Face == idx {
+			selectFontFile(file.Family, file.Style)
+			return nil
+		}
+	}
+
+	face, err := sfnt.ParseFace(data, idx)
+	if err != nil {
+		return err
+	}
+	family, style := face.Name(16), face.Name(17)
+	if family == "" {
+		family, style = face.Name(1), face.Name(2)
+	}
+
+	// Qt picks between duplicate families unpredictably, so a font that is
+	// also installed gets registered under a name of its own
+	payload, err := face.Standalone()
+	if slices.Contains(qt6.QFontDatabase_Families(), family) {
+		family = fmt.Sprintf("%s [%s]", family, filepath.Base(path))
+		payload, err = face.Renamed(family)
+	}
+	if err != nil {
+		return err
+	}
+
+	id := qt6.QFontDatabase_AddApplicationFontFromData(payload)
+	families := qt6.QFontDatabase_ApplicationFontFamilies(id)
+	if id < 0 || len(families) == 0 {
+		return errors.New("Qt could not load the font")
+	}
+
+	raw := qt6.QRawFont_FromFont(qt6.QFontDatabase_Font(families[0], style, 12))
+	fontFiles[fontKey(raw)] = &FontFile{path, idx, num, families[0], style, face, id, pixels}
+	selectFontFile(families[0], style)
+	return nil
+}
+
+// The em size in pixels of the bitmap font registered as `family`, 0 for
+// outline fonts
+func bitmapEm(family string) int {
+	for _, file := range fontFiles {
+		if file.Bitmap != nil && file.Family == family {
+			return file.Bitmap.Em()
+		}
+	}
+	return 0
+}
+
+// Rounds `px` down to a whole number of pixels per font pixel and turns
+// off what would blur them
+func bitmapFont(font *qt6.QFont, family string, px int) {
+	em := bitmapEm(family)
+	if em == 0 {
+		font.SetPixelSize(px)
+		return
+	}
+	font.SetPixelSize(max(px/em*em, em))
+	font.SetHintingPreference(qt6.QFont__PreferNoHinting)
+	font.SetStyleStrategy(font.StyleStrategy() | qt6.QFont__NoAntialias)
+}
+
+func selectFontFile(family, style string) {
+	// Styles are cached per family and a collection can add faces to one
+	// that is already selected
+	style_Family = ""
+	fontBox.SetCurrentFont(qt6.NewQFont2(family))
+	styledFont(family)
+	if idx := styleBox.FindText(style); idx >= 0 {
+		style_ignoreEvt = true
+		styleBox.SetCurrentIndex(idx)
+		style_ignoreEvt = false
+	}
+	UpdateRealFont()
+}
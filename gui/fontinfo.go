@@ -0,0 +1,189 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	info_Font GroupBox[*qt6.QTreeWidget]
+	fontGen   atomic.Int64
+)
+
+func makeInfo_Font() *qt6.QWidget {
+	tree := info_Font.Init("Font Metadata", qt6.NewQTreeWidget2())
+	tree.SetColumnCount(2)
+	tree.SetHeaderHidden(true)
+	tree.SetWordWrap(true)
+	return info_Font.group.QWidget
+}
+
+func fontInfo_Section(title string) *qt6.QTreeWidgetItem {
+	item := qt6.NewQTreeWidgetItem()
+	item.SetText(0, title)
+	info_Font.widget.AddTopLevelItem(item)
+	item.SetExpanded(true)
+	return item
+}
+
+func fontInfo_Row(parent *qt6.QTreeWidgetItem, key, value string) *qt6.QTreeWidgetItem {
+	item := qt6.NewQTreeWidgetItem()
+	item.SetText(0, key)
+	item.SetText(1, value)
+	item.SetToolTip(1, value)
+	parent.AddChild(item)
+	return item
+}
+
+func updateInfo_Font() {
+	gen := fontGen.Add(1)
+	font := fontPair.Sfnt
+	family := fontPair.Raw.FamilyName()
+	tree := info_Font.widget
+	tree.Clear()
+	info_Font.group.SetTitle(fmt.Sprintf("Font Metadata (%s)", family))
+
+	names := fontInfo_Section("Names")
+	ids := []uint16{}
+	for _, rec := range font.NameRecords() {
+		if !slices.Contains(ids, rec.ID) {
+			ids = append(ids, rec.ID)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		fontInfo_Row(names, fontInfo_NameID(id), font.Name(id))
+	}
+
+	if os2, err := font.OS2(); err == nil {
+		section := fontInfo_Section("OS/2")
+		fontInfo_Row(section, "Version", fmt.Sprint(os2.Version))
+		fontInfo_Row(section, "Vendor", os2.Vendor)
+		fontInfo_Row(section, "Weight Class", fmt.Sprintf("%d %s", os2.WeightClass, os2.WeightName()))
+		fontInfo_Row(section, "Width Class", fmt.Sprintf("%d %s", os2.WidthClass, os2.WidthName()))
+		fontInfo_Row(section, "Embedding", fmt.Sprintf(
+			"%s (fsType 0x%04X)", strings.Join(os2.Embedding(), ", "), os2.FsType,
+		))
+		fontInfo_Row(section, "Typo Ascender", fmt.Sprint(os2.TypoAscender))
+		fontInfo_Row(section, "Typo Descender", fmt.Sprint(os2.TypoDescender))
+		fontInfo_Row(section, "Typo Line Gap", fmt.Sprint(os2.TypoLineGap))
+		fontInfo_Row(section, "Win Ascent", fmt.Sprint(os2.WinAscent))
+		fontInfo_Row(section, "Win Descent", fmt.Sprint(os2.WinDescent))
+		if os2.Version >= 2 {
+			fontInfo_Row(section, "x-Height", fmt.Sprint(os2.XHeight))
+			fontInfo_Row(section, "Cap Height", fmt.Sprint(os2.CapHeight))
+		}
+
+		ranges := os2.UnicodeRanges()
+		item := fontInfo_Row(section, "Unicode Ranges", fmt.Sprintf("%d bits set", len(ranges)))
+		for _, name := range ranges {
+			fontInfo_Row(item, "", name)
+		}
+		pages := os2.CodePages()
+		item = fontInfo_Row(section, "Code Pages", fmt.Sprintf("%d bits set", len(pages)))
+		for _, name := range pages {
+			fontInfo_Row(item, "", name)
+		}
+	}
+
+	if head, err := font.Head(); err == nil {
+		section := fontInfo_Section("head")
+		fontInfo_Row(section, "Units per Em", fmt.Sprint(head.UnitsPerEm))
+		fontInfo_Row(section, "Revision", fmt.Sprintf("%.3f", head.Revision))
+		fontInfo_Row(section, "Created", head.Created.Format("2006-01-02 15:04:05"))
+		fontInfo_Row(section, "Modified", head.Modified.Format("2006-01-02 15:04:05"))
+		fontInfo_Row(section, "Bounding Box", fmt.Sprintf(
+			"%d, %d to %d, %d", head.XMin, head.YMin, head.XMax, head.YMax,
+		))
+	}
+
+	if hhea, err := font.Hhea(); err == nil {
+		section := fontInfo_Section("hhea")
+		fontInfo_Row(section, "Ascender", fmt.Sprint(hhea.Ascender))
+		fontInfo_Row(section, "Descender", fmt.Sprint(hhea.Descender))
+		fontInfo_Row(section, "Line Gap", fmt.Sprint(hhea.LineGap))
+		fontInfo_Row(section, "Max Advance", fmt.Sprint(hhea.AdvanceWidthMax))
+		fontInfo_Row(section, "Metrics", fmt.Sprint(hhea.NumberOfMetrics))
+	}
+
+	file := fontInfo_Section("File")
+	fontInfo_Row(file, "Format", font.Format())
+	fontInfo_Row(file, "Glyphs", fmt.Sprint(font.NumGlyphs()))
+	if formats := font.ColorFormats(); len(formats) > 0 {
+		fontInfo_Row(file, "Color Formats", strings.Join(formats, ", "))
+	}
+	fontInfo_Row(file, "Style", fontPair.Raw.StyleName())
+	if len(fontPair.Synthetic) > 0 {
+		fontInfo_Row(file, "Synthesized", strings.Join(fontPair.Synthetic, ", "))
+	}
+	if opened := currentFontFile(); opened != nil {
+		fontInfo_Row(file, "Path", opened.Path)
+		if opened.Faces > 1 {
+			fontInfo_Row(file, "Face", fmt.Sprintf("%d of %d in the collection", opened.Face, opened.Faces))
+		}
+		file.Child(0).SetText(1, fmt.Sprintf(
+			"%s (%s)", font.Format(), strings.TrimPrefix(filepath.Ext(opened.Path), "."),
+		))
+		if pixels := opened.Bitmap; pixels != nil {
+			file.Child(0).SetText(1, fmt.Sprintf(
+				"%s bitmap, %dpx, converted to outlines", pixels.Format, pixels.Em(),
+			))
+			fontInfo_Row(file, "Pixel Sizes", "Multiples of the em, without smoothing")
+			if len(pixels.Properties) > 0 {
+				section := fontInfo_Section("Properties")
+				for _, key := range slices.Sorted(maps.Keys(pixels.Properties)) {
+					fontInfo_Row(section, key, pixels.Properties[key])
+				}
+			}
+		}
+		tree.ResizeColumnToContents(0)
+		return
+	}
+	path := fontInfo_Row(file, "Path", "searching...")
+
+	tree.ResizeColumnToContents(0)
+
+	style := fontPair.Raw.StyleName()
+	go func() {
+		found, label := "", "Path"
+		match, err := faceMatch(family, style)
+		if err == nil && strings.EqualFold(match.Family, family) {
+			found = match.File
+			if !strings.EqualFold(match.Style, style) {
+				// Fontconfig fell back to another face of the family
+				label = "Path (family default)"
+			}
+		}
+
+		mainthread.Wait(func() {
+			if fontGen.Load() != gen {
+				return
+			}
+			if found == "" {
+				path.SetText(1, "Unknown")
+				return
+			}
+			path.SetText(0, label)
+			path.SetText(1, found)
+			path.SetToolTip(1, found)
+			file.Child(0).SetText(1, fmt.Sprintf(
+				"%s (%s)", font.Format(), strings.TrimPrefix(filepath.Ext(found), "."),
+			))
+		})
+	}()
+}
+
+func fontInfo_NameID(id uint16) string {
+	if name, ok := sfnt.NameIDs[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("Name %d", id)
+}
@@ -2,10 +2,14 @@ package gui
 
 import (
 	"fmt"
+	"fontview/sfnt"
 	"strings"
 	"sync"
+	"unicode"
+	"unicode/utf16"
 
 	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
 )
 
 type FontCache[T any] map[string]map[rune]T
@@ -24,11 +28,15 @@ var (
 	maxGlyphCache   = map[string]rune{}
 	maxGlyphSuccess = map[string]bool{}
 	maxGlyphMut     sync.Mutex
+	supportsMut     sync.Mutex
+	familyCmaps     = map[string]map[rune]uint16{}
+	familyCmapMut   sync.Mutex
+	qtCmaps         = map[string]map[rune]uint16{}
 )
 
 func maxGlyph() rune {
 	target := fontPair.Raw
-	fam := target.FamilyName()
+	fam := fontKey(target)
 	last, ok := maxGlyphCache[fam]
 	if !ok {
 		if len(blocks) == 0 {
@@ -57,22 +65,123 @@ func maxGlyph() rune {
 }
 
 func runeSupported(r rune) bool {
-	fam := fontPair.Raw.FamilyName()
+	return cachedSupports(fontKey(fontPair.Raw), fontPair.Raw, r)
+}
+
+// Like runeSupported, but for any installed family. For background
+// goroutines only, it waits on the main thread to load the family.
+func familySupports(fam string, r rune) bool {
+	return familyCmap(fam)[r] != 0
+}
+
+// Code points the current font maps to a glyph. Read on the main thread,
+// goroutines look them up in place of sharing the QRawFont.
+func currentCmap() map[rune]uint16 {
+	if fontPair.Raw == nil {
+		return nil
+	}
+	return fontCmap(fontPair.Sfnt, fontPair.Raw)
+}
+
+// The parsed cmap, or when it can't be read, whatever Qt itself maps so
+// the counts still agree with the grid. Main thread only.
+func fontCmap(face *sfnt.Font, raw *qt6.QRawFont) map[rune]uint16 {
+	if face != nil {
+		if cmap, err := face.Cmap(); err == nil {
+			return cmap
+		}
+	}
+
+	fam := fontKey(raw)
+	if cmap, ok := qtCmaps[fam]; ok {
+		return cmap
+	}
+	cmap := qtCmap(raw)
+	qtCmaps[fam] = cmap
+	return cmap
+}
+
+// Asks Qt for the glyph of every code point, a plane slice at a time
+func qtCmap(raw *qt6.QRawFont) map[rune]uint16 {
+	const chunk = 0x1000
+	ret := map[rune]uint16{}
+	runes := make([]rune, 0, chunk)
+	for start := rune(0); start <= unicode.MaxRune; start += chunk {
+		runes = runes[:0]
+		for code := start; code < start+chunk; code++ {
+			if !utf16.IsSurrogate(code) {
+				runes = append(runes, code)
+			}
+		}
+		if len(runes) == 0 {
+			continue
+		}
+
+		gids := raw.GlyphIndexesForString(string(runes))
+		if len(gids) != len(runes) {
+			// Not one glyph per code point, ask one at a time
+			for _, code := range runes {
+				if gid := raw.GlyphIndexesForString(string(code)); len(gid) > 0 && gid[0] != 0 {
+					ret[code] = uint16(gid[0])
+				}
+			}
+			continue
+		}
+		for idx, gid := range gids {
+			if gid != 0 {
+				ret[runes[idx]] = uint16(gid)
+			}
+		}
+	}
+	return ret
+}
+
+func cachedSupports(fam string, raw *qt6.QRawFont, r rune) bool {
+	supportsMut.Lock()
 	cache, ok := supportsCache[fam]
 	if !ok || cache == nil {
 		cache = map[rune]bool{}
 		supportsCache[fam] = cache
 	}
+	ret, ok := cache[r]
+	supportsMut.Unlock()
 
-	if _, ok := cache[r]; !ok {
-		cache[r] = fontPair.Raw.SupportsCharacter(uint(r))
+	if ok {
+		return ret
 	}
 
-	return cache[r]
+	ret = raw.SupportsCharacter(uint(r))
+	supportsMut.Lock()
+	cache[r] = ret
+	supportsMut.Unlock()
+	return ret
+}
+
+// Qt fonts belong to the main thread, so only the parsed cmap is kept
+func familyCmap(fam string) map[rune]uint16 {
+	familyCmapMut.Lock()
+	cmap, ok := familyCmaps[fam]
+	familyCmapMut.Unlock()
+	if ok {
+		return cmap
+	}
+
+	mainthread.Wait(func() {
+		font := qt6.NewQFont2(fam)
+		font.SetStyleStrategy(qt6.QFont__NoFontMerging)
+		raw := qt6.QRawFont_FromFont(font)
+		cmap = fontCmap(sfnt.New(raw.FontTable), raw)
+	})
+
+	familyCmapMut.Lock()
+	familyCmaps[fam] = cmap
+	familyCmapMut.Unlock()
+	return cmap
 }
 
 func makeLabel(r rune, selected bool) Render {
-	fam := fontPair.Real.Family()
+	// The whole font, a synthesized style shares the face of another
+	fam := fontPair.Real.Key()
 	targetCache := labelCache
 	if selected {
 		targetCache = selectedCache
@@ -94,6 +203,14 @@ func makeLabel(r rune, selected bool) Render {
 	if runeSupported(r) {
 		ret.Font = fontPair.Real
 		ret.Style = ""
+		if len(fontPair.Synthetic) > 0 {
+			// Drawn from the face, but emboldened or slanted by Qt
+			ret.Style = "border-bottom: 2px dashed " + sakurapine.Paint.Gold + ";"
+		}
+	} else if tbl_merging {
+		// Let Qt substitute the glyph like any other application would
+		ret.Font = fontPair.Real
+		ret.Style = "background-color: " + sakurapine.Hl.Med + ";"
 	} else {
 		ret.Text = runeFallback(r)
 		ret.Font = monoFont
@@ -0,0 +1,182 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+const glyphs_IconSize = 48
+
+var (
+	glyphs_Dialog    *qt6.QDialog
+	glyphs_List      *qt6.QListWidget
+	glyphs_Summary   *qt6.QLabel
+	glyphs_Unencoded *qt6.QCheckBox
+	glyphs_Raw       *qt6.QRawFont
+	glyphs_Drawn     map[int]bool
+)
+
+func showGlyphIDs() {
+	if glyphs_Dialog == nil {
+		makeGlyphIDs()
+	}
+	glyphs_Dialog.Show()
+	glyphs_Dialog.Raise()
+	glyphs_Dialog.ActivateWindow()
+	updateGlyphIDs()
+}
+
+func makeGlyphIDs() {
+	glyphs_Dialog = qt6.NewQDialog(window.QWidget)
+	glyphs_Dialog.SetWindowTitle("Glyphs by ID")
+	glyphs_Dialog.Resize(720, 640)
+	layout := qt6.NewQVBoxLayout(glyphs_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	glyphs_Summary = qt6.NewQLabel2()
+	glyphs_Unencoded = qt6.NewQCheckBox3("Unencoded only")
+	glyphs_Unencoded.SetToolTip("Hide every glyph reachable through the cmap")
+	glyphs_Unencoded.OnToggled(func(_ bool) { updateGlyphIDs_Filter() })
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("List the glyphs of the current font")
+	btnRefresh.OnClicked(updateGlyphIDs)
+
+	headLayout.AddWidget2(glyphs_Summary.QWidget, 1)
+	headLayout.AddWidget(glyphs_Unencoded.QWidget)
+	headLayout.AddWidget(btnRefresh.QWidget)
+
+	glyphs_List = qt6.NewQListWidget2()
+	glyphs_List.SetViewMode(qt6.QListView__IconMode)
+	glyphs_List.SetMovement(qt6.QListView__Static)
+	glyphs_List.SetResizeMode(qt6.QListView__Adjust)
+	glyphs_List.SetUniformItemSizes(true)
+	glyphs_List.SetIconSize(qt6.NewQSize2(glyphs_IconSize, glyphs_IconSize))
+	glyphs_List.SetGridSize(qt6.NewQSize2(glyphs_IconSize*2, glyphs_IconSize*2))
+	glyphs_List.SetLayoutMode(qt6.QListView__Batched)
+	glyphs_List.SetBatchSize(512)
+	glyphs_List.SetFont(monoFont)
+
+	glyphs_List.OnItemClicked(func(item *qt6.QListWidgetItem) {
+		point := item.Data(int(qt6.UserRole)).ToInt()
+		if point >= 0 {
+			onLink(fmt.Sprint(point))
+		}
+	})
+	glyphs_List.VerticalScrollBar().OnValueChanged(func(_ int) {
+		drawGlyphIDs()
+	})
+	glyphs_List.OnResizeEvent(func(super func(e *qt6.QResizeEvent), e *qt6.QResizeEvent) {
+		super(e)
+		drawGlyphIDs()
+	})
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(glyphs_List.QWidget, 1)
+}
+
+func updateGlyphIDs() {
+	font := fontPair.Sfnt
+	glyphs_Raw = fontPair.Raw
+	glyphs_Drawn = map[int]bool{}
+	glyphs_List.Clear()
+
+	num := font.NumGlyphs()
+	names := font.GlyphNames()
+	reverse, err := font.ReverseCmap()
+	if err != nil {
+		reverse = map[uint16][]rune{}
+	}
+
+	unencoded := 0
+	for gid := range num {
+		name := names[gid]
+		points := reverse[uint16(gid)]
+
+		item := qt6.NewQListWidgetItem2(fmt.Sprintf("%d\n%s", gid, name))
+		item.SetTextAlignment2(qt6.AlignHCenter | qt6.AlignTop)
+		item.SetToolTip(glyphIDs_Tip(gid, name, points))
+		if len(points) > 0 {
+			item.SetData(int(qt6.UserRole), qt6.NewQVariant4(int(points[0])))
+		} else {
+			item.SetData(int(qt6.UserRole), qt6.NewQVariant4(-1))
+			item.SetBackground(qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Hl.Low)))
+			unencoded++
+		}
+		glyphs_List.AddItemWithItem(item)
+	}
+
+	glyphs_Summary.SetText(fmt.Sprintf(
+		"<b>%s</b>: %d glyphs, %d without a code point",
+		glyphs_Raw.FamilyName(), num, unencoded,
+	))
+	updateGlyphIDs_Filter()
+}
+
+func glyphIDs_Tip(gid int, name string, points []rune) string {
+	lines := []string{fmt.Sprintf("GID %d", gid)}
+	if name != "" {
+		lines[0] += ": " + name
+	}
+	if len(points) == 0 {
+		lines = append(lines, "Not mapped by the cmap")
+	}
+	for _, r := range points {
+		lines = append(lines, fmt.Sprintf("U+%04X %s", r, runeName(r)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func updateGlyphIDs_Filter() {
+	only := glyphs_Unencoded.IsChecked()
+	for row := range glyphs_List.Count() {
+		item := glyphs_List.Item(row)
+		item.SetHidden(only && item.Data(int(qt6.UserRole)).ToInt() >= 0)
+	}
+	drawGlyphIDs()
+}
+
+// Icons are only drawn once their item scrolls into view, CJK fonts carry
+// tens of thousands of glyphs.
+func drawGlyphIDs() {
+	if glyphs_Raw == nil || glyphs_List.Count() == 0 {
+		return
+	}
+
+	view := glyphs_List.Viewport()
+	first := glyphs_List.ItemAt(qt6.NewQPoint2(0, 0))
+	last := glyphs_List.ItemAt(qt6.NewQPoint2(view.Width()-1, view.Height()-1))
+
+	start, end := 0, glyphs_List.Count()-1
+	if first != nil {
+		start = glyphs_List.Row(first)
+	}
+	if last != nil {
+		end = glyphs_List.Row(last)
+	}
+	// Partially filled rows at either edge, and a screenful at most while
+	// the batched layout hasn't placed the items yet
+	columns := max(view.Width()/(glyphs_IconSize*2), 1)
+	budget := columns * (view.Height()/(glyphs_IconSize*2) + 3)
+	start = max(start-columns, 0)
+	end = min(end+columns, glyphs_List.Count()-1)
+
+	for row := start; row <= end && budget > 0; row++ {
+		if glyphs_Drawn[row] {
+			continue
+		}
+		item := glyphs_List.Item(row)
+		if item.IsHidden() {
+			continue
+		}
+		pix := glyphPixmap(glyphs_Raw, uint(row), glyphs_IconSize, sakurapine.Text.Normal)
+		item.SetIcon(qt6.NewQIcon2(pix))
+		glyphs_Drawn[row] = true
+		budget--
+	}
+}
@@ -3,8 +3,9 @@ package gui
 import "github.com/mappu/miqt/qt6"
 
 var (
-	btnBack *qt6.QPushButton
-	btnFwd  *qt6.QPushButton
+	btnBack  *qt6.QPushButton
+	btnFwd   *qt6.QPushButton
+	styleBox *qt6.QComboBox
 )
 
 func MakeHead() *qt6.QWidget {
@@ -13,6 +14,8 @@ func MakeHead() *qt6.QWidget {
 	headLayout.SetContentsMargins(0, 0, 0, 0)
 
 	fontBox = qt6.NewQFontComboBox(nil)
+	styleBox = qt6.NewQComboBox2()
+	styleBox.SetToolTip("Style")
 	searchBox := qt6.NewQLineEdit(nil)
 
 	btnBack = qt6.NewQPushButton2()
@@ -29,15 +32,24 @@ func MakeHead() *qt6.QWidget {
 	headLayout.AddWidget3(btnFwd.QWidget, 0, qt6.AlignTop)
 	headLayout.AddWidget3(searchBox.QWidget, 1, qt6.AlignTop)
 	headLayout.AddWidget3(fontBox.QWidget, 0, qt6.AlignTop)
+	headLayout.AddWidget3(styleBox.QWidget, 0, qt6.AlignTop)
+	headLayout.AddWidget3(makeCompare_Font(), 0, qt6.AlignTop)
 
 	searchBox.SetPlaceholderText("Search glyphs")
 
 	fontHeight := fontBox.Geometry().Height()
 	searchBox.SetFixedHeight(fontHeight)
+	styleBox.SetFixedHeight(fontHeight)
 
 	fontBox.OnCurrentFontChanged(func(font *qt6.QFont) {
 		UpdateRealFont()
 	})
+	styleBox.OnCurrentIndexChanged(func(_ int) {
+		if style_ignoreEvt {
+			return
+		}
+		UpdateRealFont()
+	})
 
 	return headWidget
 }
@@ -101,7 +101,10 @@ func updateInfo() {
 
 	curR, curC := curCell.Row(), curCell.Column()
 	sheetN := tableScroller.Value() - (rows / 3)
-	char := rune(16*(sheetN+curR) + curC)
+	char, ok := cellRune(sheetN+curR, curC)
+	if !ok {
+		return
+	}
 	node := names[fmt.Sprintf("%04X", char)]
 	if node == nil {
 		if names == nil || blocks == nil {
@@ -142,6 +145,12 @@ func updateInfo() {
 	updateInfo_List(*node)
 	updateInfo_Details(*node)
 	updateInfo_RawBlock(*node)
+	updateInfo_Families(*node)
+	updateInfo_Fallback(*node)
+	updateInfo_Color(*node)
+	updateFeatures_Glyph(*node)
+	updateKerning_Glyph(*node)
+	updateOutline_Glyph(*node)
 }
 
 func make_Label(label string) *qt6.QLabel {
@@ -156,6 +165,10 @@ func makeInfo_Tab() *qt6.QWidget {
 	info_Tab = qt6.NewQTabWidget2()
 	info_Tab.AddTab(makeInfo_List(), "Metadata")
 	info_Tab.AddTab(makeInfo_RawBlock(), "Raw Data")
+	info_Tab.AddTab(makeInfo_Families(), "Fonts")
+	info_Tab.AddTab(makeInfo_Fallback(), "Fallback")
+	info_Tab.AddTab(makeInfo_Font(), "Font")
+	info_Tab.AddTab(makeInfo_Color(), "Color")
 	info_Tab.SetTabPosition(qt6.QTabWidget__South)
 	info_Tab.SetDocumentMode(true)
 	return info_Tab.QWidget
@@ -393,11 +406,25 @@ func makeInfo_Preview() *qt6.QWidget {
 
 func updateInfo_Preview(node tables.Node) {
 	font := qt6.NewQFont5(fontPair.Real)
-	font.SetPixelSize(156)
 	font.SetStyleStrategy(qt6.QFont__NoFontMerging)
+	bitmapFont(font, font.Family(), 156)
 
 	info_Preview.widget.SetFont(font)
 	info_Preview.widget.SetText(string(node.Point))
+
+	// Qt draws COLRv0 with the first palette only and can't draw COLRv1
+	color_UpdatePalettes()
+	raw := qt6.NewQRawFont4(fontPair.Raw)
+	gids := raw.GlyphIndexesForString(string(node.Point))
+	if len(gids) == 0 || gids[0] == 0 {
+		return
+	}
+	glyph := fontPair.Sfnt.GlyphColor(uint16(gids[0]))
+	if glyph.COLR == 1 || (glyph.COLR == 0 && color_Palette.CurrentIndex() > 0) {
+		if img := colorGlyph(raw, gids[0], glyph.Layers, color_Current(), 156); img != nil {
+			info_Preview.widget.SetPixmap(qt6.QPixmap_FromImage(img))
+		}
+	}
 }
 
 func makeInfo_RawBlock() *qt6.QWidget {
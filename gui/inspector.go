@@ -0,0 +1,160 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/tables"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+var (
+	inspectPanel *qt6.QDockWidget
+	inspectInput *qt6.QPlainTextEdit
+	inspectTree  *qt6.QTreeWidget
+)
+
+func MakeInspector() *qt6.QDockWidget {
+	inspectWidget := qt6.NewQWidget2()
+	inspectLayout := qt6.NewQVBoxLayout(inspectWidget)
+	inspectPanel = qt6.NewQDockWidget2("String Inspector")
+	inspectPanel.SetAllowedAreas(
+		qt6.BottomDockWidgetArea |
+			qt6.RightDockWidgetArea |
+			qt6.LeftDockWidgetArea,
+	)
+
+	inspectInput = qt6.NewQPlainTextEdit2()
+	inspectInput.SetPlaceholderText("Paste text to inspect")
+	inspectInput.SetMaximumHeight(inspectInput.FontMetrics().Height()*4 + 16)
+	inspectInput.OnTextChanged(updateInspector)
+
+	inspectTree = qt6.NewQTreeWidget2()
+	inspectTree.SetColumnCount(7)
+	inspectTree.SetHeaderLabels([]string{
+		"Glyph", "Code", "Name", "Category", "Supported", "UTF-8", "UTF-16",
+	})
+	inspectTree.SetRootIsDecorated(true)
+	inspectTree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		code := strings.Fields(item.Text(1))
+		if len(code) == 0 {
+			return
+		}
+
+		point, err := strconv.ParseInt(strings.TrimPrefix(code[0], "U+"), 16, 64)
+		if err != nil {
+			return
+		}
+		onLink(fmt.Sprint(point))
+	})
+
+	inspectLayout.AddWidget(inspectInput.QWidget)
+	inspectLayout.AddWidget2(inspectTree.QWidget, 1)
+	inspectPanel.SetWidget(inspectWidget)
+
+	return inspectPanel
+}
+
+func splitGraphemes(text string) [][]rune {
+	finder := qt6.NewQTextBoundaryFinder3(qt6.QTextBoundaryFinder__Grapheme, text)
+	bounds := map[int64]bool{}
+	for pos := finder.ToNextBoundary(); pos > 0; pos = finder.ToNextBoundary() {
+		bounds[pos] = true
+	}
+
+	ret := [][]rune{}
+	cur := []rune{}
+	var pos int64
+	for _, r := range text {
+		cur = append(cur, r)
+		pos += int64(utf16.RuneLen(r))
+		if bounds[pos] {
+			ret = append(ret, cur)
+			cur = []rune{}
+		}
+	}
+	if len(cur) > 0 {
+		ret = append(ret, cur)
+	}
+	return ret
+}
+
+func updateInspector() {
+	inspectTree.Clear()
+
+	for _, cluster := range splitGraphemes(inspectInput.ToPlainText()) {
+		codes := []string{}
+		u8, u16 := 0, 0
+		flagged := false
+		for _, r := range cluster {
+			codes = append(codes, fmt.Sprintf("U+%04X", r))
+			u8 += utf8.RuneLen(r)
+			u16 += utf16.RuneLen(r)
+			flagged = flagged || tables.InvisibleKind(r) != ""
+		}
+
+		parent := qt6.NewQTreeWidgetItem()
+		parent.SetText(0, string(cluster))
+		parent.SetFont(0, fontPair.Real)
+		parent.SetText(1, strings.Join(codes, " "))
+		parent.SetText(2, fmt.Sprintf("%d code points", len(cluster)))
+		parent.SetText(5, fmt.Sprint(u8))
+		parent.SetText(6, fmt.Sprint(u16))
+		if len(cluster) == 1 {
+			inspector_Row(parent, cluster[0])
+		}
+		if flagged {
+			inspector_Flag(parent)
+		}
+		inspectTree.AddTopLevelItem(parent)
+
+		if len(cluster) == 1 {
+			continue
+		}
+
+		for _, r := range cluster {
+			child := qt6.NewQTreeWidgetItem()
+			child.SetText(1, fmt.Sprintf("U+%04X", r))
+			inspector_Row(child, r)
+			parent.AddChild(child)
+		}
+		parent.SetExpanded(true)
+	}
+
+	for col := range inspectTree.ColumnCount() {
+		inspectTree.ResizeColumnToContents(col)
+	}
+}
+
+func inspector_Row(item *qt6.QTreeWidgetItem, r rune) {
+	kind := tables.InvisibleKind(r)
+	if kind == "" {
+		item.SetText(0, string(r))
+		item.SetFont(0, fontPair.Real)
+	} else {
+		item.SetText(0, "<"+kind+">")
+		item.SetFont(0, monoFont)
+		inspector_Flag(item)
+	}
+
+	item.SetText(2, runeName(r))
+	item.SetText(3, tables.GeneralCategory(r))
+	if runeSupported(r) {
+		item.SetText(4, "Yes")
+	} else {
+		item.SetText(4, "No")
+	}
+	item.SetText(5, fmt.Sprint(utf8.RuneLen(r)))
+	item.SetText(6, fmt.Sprint(utf16.RuneLen(r)))
+}
+
+func inspector_Flag(item *qt6.QTreeWidgetItem) {
+	brush := qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Paint.Love))
+	for col := range 7 {
+		item.SetForeground(col, brush)
+	}
+	item.SetToolTip(0, "Contains invisible or formatting characters")
+}
@@ -0,0 +1,326 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"fontview/tables"
+	"math"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	kern_Dialog  *qt6.QDialog
+	kern_Summary *qt6.QLabel
+	kern_Pairs   *qt6.QTableWidget
+	kern_Without *qt6.QLabel
+	kern_With    *qt6.QLabel
+	kern_Left    *qt6.QLineEdit
+	kern_Right   *qt6.QLineEdit
+	kern_Matrix  *qt6.QTableWidget
+
+	kern_Font *sfnt.Font
+	kern_Raw  *qt6.QRawFont
+	kernGen   atomic.Int64
+)
+
+func showKerning() {
+	if kern_Dialog == nil {
+		makeKerning()
+	}
+	kern_Dialog.Show()
+	kern_Dialog.Raise()
+	kern_Dialog.ActivateWindow()
+	kern_Font = fontPair.Sfnt
+	kern_Raw = fontPair.Raw
+	updateKerning_Glyph(curNode)
+	updateKerning_Matrix()
+}
+
+func makeKerning() {
+	kern_Dialog = qt6.NewQDialog(window.QWidget)
+	kern_Dialog.SetWindowTitle("Kerning")
+	kern_Dialog.Resize(760, 680)
+	layout := qt6.NewQVBoxLayout(kern_Dialog.QWidget)
+
+	tabs := qt6.NewQTabWidget2()
+	tabs.AddTab(makeKerning_Pairs(), "Selected Glyph")
+	tabs.AddTab(makeKerning_Matrix(), "Matrix")
+	layout.AddWidget(tabs.QWidget)
+}
+
+func makeKerning_Pairs() *qt6.QWidget {
+	widget := qt6.NewQWidget2()
+	layout := qt6.NewQVBoxLayout(widget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+	kern_Summary = qt6.NewQLabel2()
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("Read the kerning of the current font")
+	btnRefresh.OnClicked(func() {
+		kern_Font = fontPair.Sfnt
+		kern_Raw = fontPair.Raw
+		updateKerning_Glyph(curNode)
+		updateKerning_Matrix()
+	})
+	headLayout.AddWidget2(kern_Summary.QWidget, 1)
+	headLayout.AddWidget(btnRefresh.QWidget)
+
+	kern_Pairs = qt6.NewQTableWidget2()
+	kern_Pairs.SetColumnCount(5)
+	kern_Pairs.SetHorizontalHeaderLabels([]string{
+		"Pair", "Left", "Right", "Value", "Source",
+	})
+	kern_Pairs.HorizontalHeader().SetStretchLastSection(true)
+	kern_Pairs.VerticalHeader().SetVisible(false)
+	kern_Pairs.SetEditTriggers(qt6.QAbstractItemView__NoEditTriggers)
+	kern_Pairs.SetSelectionBehavior(qt6.QAbstractItemView__SelectRows)
+	kern_Pairs.SetSelectionMode(qt6.QAbstractItemView__SingleSelection)
+	kern_Pairs.OnItemSelectionChanged(updateKerning_Preview)
+
+	previewGroup := GroupBox[*qt6.QWidget]{}
+	previewWidget := previewGroup.Init("Preview", qt6.NewQWidget2())
+	previewLayout := qt6.NewQHBoxLayout(previewWidget)
+	kern_Without = qt6.NewQLabel2()
+	kern_Without.SetAlignment(qt6.AlignCenter)
+	kern_Without.SetToolTip("Without kerning")
+	kern_With = qt6.NewQLabel2()
+	kern_With.SetAlignment(qt6.AlignCenter)
+	kern_With.SetToolTip("With kerning")
+	previewLayout.AddWidget(kern_Without.QWidget)
+	previewLayout.AddWidget(kern_With.QWidget)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(kern_Pairs.QWidget, 1)
+	layout.AddWidget(previewGroup.group.QWidget)
+	return widget
+}
+
+func makeKerning_Matrix() *qt6.QWidget {
+	widget := qt6.NewQWidget2()
+	layout := qt6.NewQVBoxLayout(widget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+	kern_Left = qt6.NewQLineEdit3("A-Z")
+	kern_Left.SetToolTip("Left characters, ranges like A-Z allowed")
+	kern_Right = qt6.NewQLineEdit3("a-z")
+	kern_Right.SetToolTip("Right characters, ranges like a-z allowed")
+	btnApply := qt6.NewQPushButton3("Show")
+	btnApply.OnClicked(updateKerning_Matrix)
+	kern_Left.OnReturnPressed(updateKerning_Matrix)
+	kern_Right.OnReturnPressed(updateKerning_Matrix)
+	headLayout.AddWidget(qt6.NewQLabel3("Left").QWidget)
+	headLayout.AddWidget2(kern_Left.QWidget, 1)
+	headLayout.AddWidget(qt6.NewQLabel3("×").QWidget)
+	headLayout.AddWidget(qt6.NewQLabel3("Right").QWidget)
+	headLayout.AddWidget2(kern_Right.QWidget, 1)
+	headLayout.AddWidget(btnApply.QWidget)
+
+	kern_Matrix = qt6.NewQTableWidget2()
+	kern_Matrix.SetEditTriggers(qt6.QAbstractItemView__NoEditTriggers)
+	kern_Matrix.HorizontalHeader().SetDefaultSectionSize(40)
+	kern_Matrix.VerticalHeader().SetDefaultSectionSize(28)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(kern_Matrix.QWidget, 1)
+	return widget
+}
+
+var codeNotation = regexp.MustCompile(`U\+([0-9A-Fa-f]{4,6})`)
+
+// Expands ranges like A-Z, a literal hyphen goes first or last. Characters
+// can be written as U+XXXX too.
+func parseCharset(text string) []rune {
+	text = codeNotation.ReplaceAllStringFunc(text, func(code string) string {
+		point, _ := strconv.ParseInt(code[2:], 16, 32)
+		return string(rune(point))
+	})
+	chars := []rune(text)
+	ret := []rune{}
+	for idx := 0; idx < len(chars); idx++ {
+		if idx+2 < len(chars) && chars[idx+1] == '-' && chars[idx] < chars[idx+2] {
+			for r := chars[idx]; r <= chars[idx+2]; r++ {
+				ret = append(ret, r)
+			}
+			idx += 2
+			continue
+		}
+		if chars[idx] != ' ' {
+			ret = append(ret, chars[idx])
+		}
+	}
+	return ret
+}
+
+// Character for a glyph where the cmap has one, the glyph name otherwise
+func kerning_Label(gid uint16, reverse map[uint16][]rune, names []string) string {
+	if points := reverse[gid]; len(points) > 0 {
+		return string(points[0])
+	}
+	if int(gid) < len(names) && names[gid] != "" {
+		return names[gid]
+	}
+	return fmt.Sprintf("#%d", gid)
+}
+
+// Rereads the kerning once the main window moved to another font. Called by
+// UpdateRealFont, so does nothing while the dialog is closed.
+func updateKerning_Font() {
+	if kern_Dialog == nil || !kern_Dialog.IsVisible() || fontKey(kern_Raw) == fontKey(fontPair.Raw) {
+		return
+	}
+	kern_Font = fontPair.Sfnt
+	kern_Raw = fontPair.Raw
+	updateKerning_Glyph(curNode)
+	updateKerning_Matrix()
+}
+
+// Lists the pairs of the selected glyph. Called by updateInfo, so does
+// nothing while the dialog is closed.
+func updateKerning_Glyph(node tables.Node) {
+	if kern_Dialog == nil || !kern_Dialog.IsVisible() || kern_Font == nil {
+		return
+	}
+
+	gen := kernGen.Add(1)
+	font := kern_Font
+	fam := kern_Raw.FamilyName()
+	kern_Pairs.SetRowCount(0)
+	kern_Summary.SetText(fmt.Sprintf("<b>%s</b>: searching...", fam))
+
+	go func() {
+		cmap, _ := font.Cmap()
+		gid, ok := cmap[node.Point]
+		var asLeft, asRight []sfnt.KernPair
+		if ok {
+			asLeft, asRight = font.KernPairs(gid)
+		}
+		reverse, _ := font.ReverseCmap()
+		names := font.GlyphNames()
+
+		mainthread.Wait(func() {
+			if kernGen.Load() != gen {
+				return
+			}
+			if !ok {
+				kern_Summary.SetText(fmt.Sprintf("<b>%s</b>: U+%s is not in the cmap", fam, node.Code))
+				return
+			}
+
+			kern_Pairs.SetSortingEnabled(false)
+			pairs := append(asLeft, asRight...)
+			for _, pair := range pairs {
+				left := kerning_Label(pair.Left, reverse, names)
+				right := kerning_Label(pair.Right, reverse, names)
+
+				row := kern_Pairs.RowCount()
+				kern_Pairs.InsertRow(row)
+				item := qt6.NewQTableWidgetItem2(left + right)
+				item.SetFont(fontPair.Real)
+				item.SetData(int(qt6.UserRole), qt6.NewQVariant4(int(pair.Left)<<16|int(pair.Right)))
+				kern_Pairs.SetItem(row, 0, item)
+				kern_Pairs.SetItem(row, 1, qt6.NewQTableWidgetItem2(left))
+				kern_Pairs.SetItem(row, 2, qt6.NewQTableWidgetItem2(right))
+				kern_Pairs.SetItem(row, 3, report_Number(qt6.NewQVariant4(pair.Value)))
+				kern_Pairs.SetItem(row, 4, qt6.NewQTableWidgetItem2(pair.Source))
+			}
+			kern_Pairs.SetSortingEnabled(true)
+			kern_Pairs.ResizeColumnsToContents()
+
+			kern_Summary.SetText(fmt.Sprintf(
+				"<b>%s</b>: %d pairs with %s on the left, %d on the right",
+				fam, len(asLeft), string(node.Point), len(asRight),
+			))
+			if len(pairs) > 0 {
+				kern_Pairs.SelectRow(0)
+			}
+		})
+	}()
+}
+
+func updateKerning_Preview() {
+	item := kern_Pairs.Item(kern_Pairs.CurrentRow(), 0)
+	if item == nil {
+		kern_Without.Clear()
+		kern_With.Clear()
+		return
+	}
+
+	// The row rather than a lookup, the pair may come from either table
+	row := kern_Pairs.CurrentRow()
+	key := item.Data(int(qt6.UserRole)).ToInt()
+	left, right := uint16(key>>16), uint16(key)
+	value := kern_Pairs.Item(row, 3).Data(int(qt6.DisplayRole)).ToInt()
+
+	gids := []uint{uint(left), uint(right)}
+	shift := float64(value) * kern_Raw.PixelSize() / kern_Raw.UnitsPerEm()
+	kern_Without.SetPixmap(glyphRunPixmap(kern_Raw, gids, nil, 128, sakurapine.Text.Normal))
+	kern_With.SetPixmap(glyphRunPixmap(kern_Raw, gids, []float64{shift}, 128, sakurapine.Text.Normal))
+}
+
+func updateKerning_Matrix() {
+	if kern_Font == nil {
+		return
+	}
+
+	lefts, rights := parseCharset(kern_Left.Text()), parseCharset(kern_Right.Text())
+	cmap, _ := kern_Font.Cmap()
+
+	values := make([][]int, len(lefts))
+	extreme := 1
+	for row, l := range lefts {
+		values[row] = make([]int, len(rights))
+		for col, r := range rights {
+			left, okL := cmap[l]
+			right, okR := cmap[r]
+			if !okL || !okR {
+				continue
+			}
+			if pair, ok := kern_Font.Kerning(left, right); ok {
+				values[row][col] = pair.Value
+				extreme = max(extreme, int(math.Abs(float64(pair.Value))))
+			}
+		}
+	}
+
+	kern_Matrix.Clear()
+	kern_Matrix.SetRowCount(len(lefts))
+	kern_Matrix.SetColumnCount(len(rights))
+	labels := func(runes []rune) []string {
+		ret := []string{}
+		for _, r := range runes {
+			ret = append(ret, string(r))
+		}
+		return ret
+	}
+	kern_Matrix.SetVerticalHeaderLabels(labels(lefts))
+	kern_Matrix.SetHorizontalHeaderLabels(labels(rights))
+
+	for row := range lefts {
+		for col := range rights {
+			value := values[row][col]
+			item := qt6.NewQTableWidgetItem()
+			item.SetTextAlignment2(qt6.AlignCenter)
+			item.SetToolTip(fmt.Sprintf("%c%c: %d", lefts[row], rights[col], value))
+			if value != 0 {
+				item.SetText(fmt.Sprint(value))
+				color := qt6.NewQColor6(sakurapine.Paint.Love)
+				if value > 0 {
+					color = qt6.NewQColor6(sakurapine.Paint.Tree)
+				}
+				color.SetAlphaF(float32(0.15 + 0.85*math.Abs(float64(value))/float64(extreme)))
+				item.SetBackground(qt6.NewQBrush3(color))
+			}
+			kern_Matrix.SetItem(row, col, item)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/tables"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+// How far a language is covered, with the exemplars the font lacks
+type LanguageSupport struct {
+	tables.Language
+	Missing []rune
+}
+
+var (
+	lang_Dialog  *qt6.QDialog
+	lang_Summary *qt6.QLabel
+	lang_Filter  *qt6.QLineEdit
+	lang_Tree    *qt6.QTreeWidget
+	lang_Gen     atomic.Int64
+)
+
+func showLanguages() {
+	if lang_Dialog == nil {
+		makeLanguages()
+	}
+	lang_Dialog.Show()
+	lang_Dialog.Raise()
+	lang_Dialog.ActivateWindow()
+	updateLanguages()
+}
+
+func makeLanguages() {
+	lang_Dialog = qt6.NewQDialog(window.QWidget)
+	lang_Dialog.SetWindowTitle("Language Support")
+	lang_Dialog.Resize(680, 640)
+	layout := qt6.NewQVBoxLayout(lang_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	lang_Filter = qt6.NewQLineEdit(nil)
+	lang_Filter.SetPlaceholderText("Filter languages")
+	lang_Filter.SetClearButtonEnabled(true)
+	lang_Filter.OnTextChanged(func(_ string) { filterLanguages() })
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("Check the current font")
+	btnRefresh.OnClicked(updateLanguages)
+	headLayout.AddWidget2(lang_Filter.QWidget, 1)
+	headLayout.AddWidget(btnRefresh.QWidget)
+
+	lang_Summary = qt6.NewQLabel2()
+	lang_Summary.SetWordWrap(true)
+
+	lang_Tree = qt6.NewQTreeWidget2()
+	lang_Tree.SetColumnCount(3)
+	lang_Tree.SetHeaderLabels([]string{"Language", "Missing", "Name"})
+	lang_Tree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		point := item.Data(0, int(qt6.UserRole))
+		if point.IsValid() {
+			onLink(fmt.Sprint(point.ToInt()))
+		}
+	})
+
+	note := qt6.NewQLabel3("Exemplar characters from CLDR, the letters everyday text in each language uses")
+	note.SetWordWrap(true)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget(lang_Summary.QWidget)
+	layout.AddWidget2(lang_Tree.QWidget, 1)
+	layout.AddWidget(note.QWidget)
+}
+
+func updateLanguages() {
+	gen := lang_Gen.Add(1)
+	fam := fontKey(fontPair.Raw)
+	cmap := currentCmap()
+	lang_Summary.SetText(fmt.Sprintf("<b>%s</b>: checking...", fam))
+
+	go func() {
+		ret := []LanguageSupport{}
+		for _, lang := range tables.ParseExemplars() {
+			ret = append(ret, LanguageSupport{lang, lang.Missing(func(r rune) bool {
+				return cmap[r] != 0
+			})})
+		}
+		mainthread.Wait(func() {
+			if lang_Gen.Load() != gen {
+				return
+			}
+			updateLanguages_Tree(fam, ret)
+		})
+	}()
+}
+
+func updateLanguages_Tree(fam string, langs []LanguageSupport) {
+	lang_Tree.Clear()
+
+	groups := []struct {
+		title string
+		langs []LanguageSupport
+	}{{title: "Fully supported"}, {title: "Partially supported"}, {title: "Not supported"}}
+	for _, lang := range langs {
+		switch len(lang.Missing) {
+		case 0:
+			groups[0].langs = append(groups[0].langs, lang)
+		case len(lang.Exemplars):
+			groups[2].langs = append(groups[2].langs, lang)
+		default:
+			groups[1].langs = append(groups[1].langs, lang)
+		}
+	}
+	// The languages closest to full support first
+	slices.SortStableFunc(groups[1].langs, func(a, b LanguageSupport) int {
+		return len(a.Missing) - len(b.Missing)
+	})
+
+	for _, group := range groups {
+		parent := qt6.NewQTreeWidgetItem()
+		parent.SetText(0, fmt.Sprintf("%s (%d)", group.title, len(group.langs)))
+		for _, lang := range group.langs {
+			item := qt6.NewQTreeWidgetItem()
+			item.SetText(0, fmt.Sprintf("%s [%s]", lang.Name, lang.Code))
+			item.SetText(1, fmt.Sprintf("%d / %d", len(lang.Missing), len(lang.Exemplars)))
+			if len(lang.Missing) > 0 && len(lang.Missing) < len(lang.Exemplars) {
+				item.SetText(2, string(lang.Missing))
+				item.SetToolTip(2, string(lang.Missing))
+			}
+			for _, r := range lang.Missing {
+				child := qt6.NewQTreeWidgetItem()
+				child.SetText(0, fmt.Sprintf("U+%04X %s", r, string(r)))
+				child.SetText(2, runeName(r))
+				child.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(int(r)))
+				item.AddChild(child)
+			}
+			parent.AddChild(item)
+		}
+		lang_Tree.AddTopLevelItem(parent)
+	}
+	lang_Tree.TopLevelItem(1).SetExpanded(true)
+	lang_Tree.ResizeColumnToContents(0)
+	lang_Tree.ResizeColumnToContents(1)
+
+	lang_Summary.SetText(fmt.Sprintf(
+		"<b>%s</b>: %d languages fully, %d partially and %d not supported",
+		fam, len(groups[0].langs), len(groups[1].langs), len(groups[2].langs),
+	))
+	filterLanguages()
+}
+
+// Hides the languages whose name or code doesn't contain the filter text
+func filterLanguages() {
+	text := strings.ToLower(strings.TrimSpace(lang_Filter.Text()))
+	for idx := range lang_Tree.TopLevelItemCount() {
+		parent := lang_Tree.TopLevelItem(idx)
+		for n := range parent.ChildCount() {
+			item := parent.Child(n)
+			item.SetHidden(text != "" && !strings.Contains(strings.ToLower(item.Text(0)), text))
+		}
+		if text != "" {
+			parent.SetExpanded(true)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"fontview/sfnt"
+	"fontview/tables"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	lint_Dialog  *qt6.QDialog
+	lint_Summary *qt6.QLabel
+	lint_Tree    *qt6.QTreeWidget
+	lint_Issues  []tables.LintIssue
+	lint_Family  string
+	lint_Gen     atomic.Int64
+)
+
+func showLint() {
+	if lint_Dialog == nil {
+		makeLint()
+	}
+	lint_Dialog.Show()
+	lint_Dialog.Raise()
+	lint_Dialog.ActivateWindow()
+	updateLint()
+}
+
+func makeLint() {
+	lint_Dialog = qt6.NewQDialog(window.QWidget)
+	lint_Dialog.SetWindowTitle("Font QA Lint")
+	lint_Dialog.Resize(760, 600)
+	layout := qt6.NewQVBoxLayout(lint_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	lint_Summary = qt6.NewQLabel2()
+	lint_Summary.SetWordWrap(true)
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("Lint the current font")
+	btnRefresh.OnClicked(updateLint)
+	btnExport := qt6.NewQPushButton3("Export...")
+	btnExport.OnClicked(lint_ExportEvt)
+
+	headLayout.AddWidget2(lint_Summary.QWidget, 1)
+	headLayout.AddWidget(btnRefresh.QWidget)
+	headLayout.AddWidget(btnExport.QWidget)
+
+	lint_Tree = qt6.NewQTreeWidget2()
+	lint_Tree.SetColumnCount(4)
+	lint_Tree.SetHeaderLabels([]string{"Check", "Glyph", "Detail", "Name"})
+	lint_Tree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		if item.Parent() == nil {
+			return
+		}
+		onLink(fmt.Sprint(item.Data(0, int(qt6.UserRole)).ToInt()))
+	})
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(lint_Tree.QWidget, 1)
+}
+
+// Gathers the glyph of every cmap entry of the current font, including the
+// ones mapped to .notdef, outlines from Qt so CFF fonts are covered too.
+// Glyphs are read a batch at a time on the main thread, which owns the
+// QRawFont and the tables behind `face`. Nil once `gen` is stale.
+func lintGlyphs(face *sfnt.Font, raw *qt6.QRawFont, cmap map[rune]uint16, gen int64) []tables.LintGlyph {
+	points := []rune{}
+	for r := range cmap {
+		points = append(points, r)
+	}
+	slices.Sort(points)
+
+	const batch = 256
+	ret := make([]tables.LintGlyph, 0, len(points))
+	for start := 0; start < len(points); start += batch {
+		if lint_Gen.Load() != gen {
+			return nil
+		}
+		chunk := points[start:min(start+batch, len(points))]
+		ret = append(ret, mainthread.Wait2(func() []tables.LintGlyph {
+			ret := make([]tables.LintGlyph, len(chunk))
+			for idx, r := range chunk {
+				gid := cmap[r]
+				color := face.GlyphColor(gid)
+				advance, _ := face.HMetrics(gid)
+				ret[idx] = tables.LintGlyph{
+					Point:    r,
+					Glyph:    gid,
+					Empty:    raw.PathForGlyph(uint(gid)).ElementCount() == 0,
+					Color:    color.COLR >= 0 || color.SVG || len(color.Bitmaps) > 0,
+					Advance:  advance,
+					Assigned: runeAssigned(r),
+				}
+			}
+			return ret
+		})...)
+	}
+	return ret
+}
+
+func updateLint() {
+	gen := lint_Gen.Add(1)
+	fam := fontKey(fontPair.Raw)
+	lint_Tree.Clear()
+	face, raw := fontPair.Sfnt, fontPair.Raw
+	cmap, err := face.RawCmap()
+	if err != nil {
+		lint_Summary.SetText(fmt.Sprintf("<b>%s</b>: %s", fam, err))
+		return
+	}
+	lint_Summary.SetText(fmt.Sprintf("<b>%s</b>: checking...", fam))
+
+	go func() {
+		glyphs := lintGlyphs(face, raw, cmap, gen)
+		issues := tables.Lint(glyphs)
+		mainthread.Wait(func() {
+			if lint_Gen.Load() != gen {
+				return
+			}
+			lint_Family = fam
+			lint_Issues = issues
+			updateLint_Tree()
+			lint_Summary.SetText(fmt.Sprintf(
+				"<b>%s</b>: %d issues in %d mapped code points", fam, len(issues), len(glyphs),
+			))
+		})
+	}()
+}
+
+func updateLint_Tree() {
+	lint_Tree.Clear()
+	byCheck := map[string][]tables.LintIssue{}
+	for _, issue := range lint_Issues {
+		byCheck[issue.Check] = append(byCheck[issue.Check], issue)
+	}
+
+	for _, check := range tables.LintChecks {
+		issues := byCheck[check.ID]
+		parent := qt6.NewQTreeWidgetItem()
+		parent.SetText(0, check.Title)
+		parent.SetText(1, fmt.Sprint(len(issues)))
+		if len(issues) == 0 {
+			parent.SetForeground(0, qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Text.Muted)))
+		}
+		for _, issue := range issues {
+			child := qt6.NewQTreeWidgetItem()
+			child.SetText(0, fmt.Sprintf("U+%04X %s", issue.Point, string(issue.Point)))
+			child.SetText(1, fmt.Sprint(issue.Glyph))
+			child.SetText(2, issue.Detail)
+			child.SetText(3, runeName(issue.Point))
+			child.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(int(issue.Point)))
+			parent.AddChild(child)
+		}
+		lint_Tree.AddTopLevelItem(parent)
+	}
+	for col := range 3 {
+		lint_Tree.ResizeColumnToContents(col)
+	}
+}
+
+func lint_ExportEvt() {
+	name := strings.ReplaceAll(lint_Family, " ", "_") + "-lint"
+	path := qt6.QFileDialog_GetSaveFileName4(
+		lint_Dialog.QWidget, "Export lint", name+".json", "JSON (*.json)",
+	)
+	if path == "" {
+		return
+	}
+
+	buf := bytes.Buffer{}
+	err := tables.WriteLintJSON(&buf, lint_Family, lint_Issues)
+	if err == nil {
+		err = os.WriteFile(path, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		qt6.QMessageBox_Critical(lint_Dialog.QWidget, "Export failed", err.Error())
+	}
+}
@@ -0,0 +1,55 @@
+package gui
+
+import "github.com/mappu/miqt/qt6"
+
+var (
+	menu_File  *qt6.QMenu
+	menu_View  *qt6.QMenu
+	menu_Tools *qt6.QMenu
+)
+
+func MakeMenu() {
+	bar := window.MenuBar()
+	menu_File = bar.AddMenuWithTitle("&File")
+	menu_View = bar.AddMenuWithTitle("&View")
+	menu_Tools = bar.AddMenuWithTitle("&Tools")
+
+	open := menu_File.AddActionWithText("Open Font File...")
+	open.SetShortcut(qt6.NewQKeySequence2("Ctrl+O"))
+	open.OnTriggered(openFontFileEvt)
+
+	menu_View.AddAction(infoPanel.ToggleViewAction())
+	menu_View.AddAction(inspectPanel.ToggleViewAction())
+	menu_View.AddAction(navPanel.ToggleViewAction())
+	menu_View.AddAction(varPanel.ToggleViewAction())
+	menu_View.AddSeparator()
+
+	compact := menu_View.AddActionWithText("Supported Glyphs Only")
+	compact.SetCheckable(true)
+	compact.SetToolTip("Pack the glyphs the font supports densely, skipping everything else")
+	compact.OnToggled(toggleCompact)
+
+	compare := menu_View.AddActionWithText("Compare Fonts")
+	compare.SetCheckable(true)
+	compare.SetToolTip("Show a second font next to the grid, colouring the glyphs only one of them has")
+	compare.OnToggled(toggleCompare)
+
+	merging := menu_View.AddActionWithText("Fallback Merging")
+	merging.SetCheckable(true)
+	merging.SetToolTip("Render missing glyphs from fallback fonts, highlighting the substituted cells")
+	merging.OnToggled(toggleMerging)
+
+	menu_Tools.AddActionWithText("Mojibake Detective...").OnTriggered(showMojibake)
+	menu_Tools.AddActionWithText("Document Coverage...").OnTriggered(showCoverage)
+	menu_Tools.AddActionWithText("Coverage Report...").OnTriggered(showReport)
+	menu_Tools.AddActionWithText("Language Support...").OnTriggered(showLanguages)
+	menu_Tools.AddActionWithText("Font QA Lint...").OnTriggered(showLint)
+	menu_Tools.AddActionWithText("Glyphs by ID...").OnTriggered(showGlyphIDs)
+	menu_Tools.AddActionWithText("OpenType Features...").OnTriggered(showFeatures)
+	menu_Tools.AddActionWithText("Kerning...").OnTriggered(showKerning)
+	menu_Tools.AddActionWithText("Outline Inspector...").OnTriggered(showOutline)
+	menu_Tools.AddActionWithText("Compare Font Versions...").OnTriggered(showDiff)
+	menu_Tools.AddActionWithText("Export Glyphs...").OnTriggered(func() {
+		showExport(string(curNode.Point))
+	})
+}
@@ -0,0 +1,235 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/tables"
+	"html"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	moji_Dialog    *qt6.QDialog
+	moji_Input     *qt6.QPlainTextEdit
+	moji_FileLabel *qt6.QLabel
+	moji_Decodings GroupBox[*qt6.QTableWidget]
+	moji_Repairs   GroupBox[*qt6.QListWidget]
+	moji_Points    GroupBox[*qt6.QScrollArea]
+	moji_PointList *qt6.QLabel
+	moji_Timer     *qt6.QTimer
+
+	moji_Data      []byte
+	moji_Decoded   []tables.Decoding
+	moji_Fixes     []tables.Repair
+	moji_ignoreEvt = false
+	moji_Gen       atomic.Int64
+)
+
+func showMojibake() {
+	if moji_Dialog == nil {
+		makeMojibake()
+	}
+	moji_Dialog.Show()
+	moji_Dialog.Raise()
+	moji_Dialog.ActivateWindow()
+}
+
+func makeMojibake() {
+	moji_Dialog = qt6.NewQDialog(window.QWidget)
+	moji_Dialog.SetWindowTitle("Mojibake Detective")
+	moji_Dialog.Resize(760, 680)
+	layout := qt6.NewQVBoxLayout(moji_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	btnOpen := qt6.NewQPushButton3("Open file...")
+	btnOpen.OnClicked(moji_OpenEvt)
+	moji_FileLabel = qt6.NewQLabel3("Pasted text")
+	headLayout.AddWidget(btnOpen.QWidget)
+	headLayout.AddWidget2(moji_FileLabel.QWidget, 1)
+
+	moji_Input = qt6.NewQPlainTextEdit2()
+	moji_Input.SetPlaceholderText("Paste garbled text here")
+	moji_Input.OnTextChanged(func() {
+		if moji_ignoreEvt {
+			moji_ignoreEvt = false
+			return
+		}
+		moji_Data = nil
+		moji_FileLabel.SetText("Pasted text")
+		// Waits for a pause in typing
+		moji_Timer.Start(300)
+	})
+	moji_Timer = qt6.NewQTimer()
+	moji_Timer.OnTimerEvent(func(super func(evt *qt6.QTimerEvent), evt *qt6.QTimerEvent) {
+		moji_Timer.Stop()
+		updateMojibake()
+	})
+
+	tbl := moji_Decodings.Init("Decodings", qt6.NewQTableWidget2())
+	tbl.SetColumnCount(3)
+	tbl.SetHorizontalHeaderLabels([]string{"Encoding", "Invalid", "Text"})
+	tbl.HorizontalHeader().SetStretchLastSection(true)
+	tbl.VerticalHeader().SetVisible(false)
+	tbl.SetEditTriggers(qt6.QAbstractItemView__NoEditTriggers)
+	tbl.SetSelectionBehavior(qt6.QAbstractItemView__SelectRows)
+	tbl.SetSelectionMode(qt6.QAbstractItemView__SingleSelection)
+	tbl.OnCurrentCellChanged(func(row, _, _, _ int) {
+		if row < 0 || row >= len(moji_Decoded) {
+			return
+		}
+		updateMojibake_Repairs(moji_Decoded[row].Text)
+	})
+
+	list := moji_Repairs.Init("Repairs", qt6.NewQListWidget2())
+	list.OnCurrentRowChanged(func(row int) {
+		if row < 0 || row > len(moji_Fixes) {
+			return
+		}
+		if row == 0 {
+			cur := tbl.CurrentRow()
+			if cur >= 0 && cur < len(moji_Decoded) {
+				updateMojibake_Points(moji_Decoded[cur].Text)
+			}
+			return
+		}
+		updateMojibake_Points(moji_Fixes[row-1].Text)
+	})
+
+	moji_PointList = qt6.NewQLabel2()
+	moji_PointList.SetAlignment(qt6.AlignTop | qt6.AlignLeft)
+	moji_PointList.SetTextFormat(qt6.RichText)
+	moji_PointList.SetTextInteractionFlags(qt6.TextSelectableByMouse | qt6.LinksAccessibleByMouse)
+	moji_PointList.OnLinkActivated(onLink)
+	scroll := moji_Points.Init("Code Points", qt6.NewQScrollArea2())
+	scroll.SetWidgetResizable(true)
+	scroll.SetWidget(moji_PointList.QWidget)
+
+	bodyWidget := qt6.NewQWidget2()
+	bodyLayout := qt6.NewQHBoxLayout(bodyWidget)
+	bodyLayout.SetContentsMargins(0, 0, 0, 0)
+	bodyLayout.AddWidget2(moji_Repairs.group.QWidget, 1)
+	bodyLayout.AddWidget2(moji_Points.group.QWidget, 1)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(moji_Input.QWidget, 1)
+	layout.AddWidget2(moji_Decodings.group.QWidget, 1)
+	layout.AddWidget2(bodyWidget, 2)
+}
+
+func moji_OpenEvt() {
+	path := qt6.QFileDialog_GetOpenFileName2(moji_Dialog.QWidget, "Open file")
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		moji_FileLabel.SetText(err.Error())
+		return
+	}
+
+	moji_Data = data
+	moji_FileLabel.SetText(fmt.Sprintf("%s (%d bytes)", filepath.Base(path), len(data)))
+	moji_ignoreEvt = true
+	moji_Input.SetPlainText(strings.ToValidUTF8(string(data), "�"))
+	updateMojibake()
+}
+
+func updateMojibake() {
+	data := moji_Data
+	if data == nil {
+		data = []byte(moji_Input.ToPlainText())
+	}
+
+	moji_Decoded = tables.DecodeAll(data)
+	tbl := moji_Decodings.widget
+	tbl.SetRowCount(len(moji_Decoded))
+
+	selected := 0
+	for row, dec := range moji_Decoded {
+		preview := []rune(strings.SplitN(dec.Text, "\n", 2)[0])
+		if len(preview) > 120 {
+			preview = append(preview[:120], '…')
+		}
+
+		tbl.SetItem(row, 0, qt6.NewQTableWidgetItem2(dec.Encoding))
+		tbl.SetItem(row, 1, qt6.NewQTableWidgetItem2(fmt.Sprint(dec.Invalid)))
+		tbl.SetItem(row, 2, qt6.NewQTableWidgetItem2(string(preview)))
+		if dec.Encoding == "UTF-8" {
+			selected = row
+		}
+	}
+	tbl.ResizeColumnToContents(0)
+	tbl.ResizeColumnToContents(1)
+
+	if len(moji_Decoded) > 0 {
+		tbl.SetCurrentCell(selected, 0)
+		updateMojibake_Repairs(moji_Decoded[selected].Text)
+	}
+}
+
+// Repairs can take many passes over the text, so they are searched in the
+// background and listed once the text hasn't changed since
+func updateMojibake_Repairs(text string) {
+	gen := moji_Gen.Add(1)
+	moji_Fixes = nil
+	list := moji_Repairs.widget
+	list.Clear()
+	list.AddItem("As decoded")
+	list.SetCurrentRow(0)
+
+	go func() {
+		fixes := tables.RepairMojibake(text)
+		mainthread.Wait(func() {
+			if moji_Gen.Load() != gen {
+				return
+			}
+			moji_Fixes = fixes
+			for _, fix := range fixes {
+				steps := slices.Clone(fix.Steps)
+				slices.Reverse(steps)
+				preview := []rune(strings.SplitN(fix.Text, "\n", 2)[0])
+				if len(preview) > 40 {
+					preview = append(preview[:40], '…')
+				}
+				list.AddItem(fmt.Sprintf(
+					"UTF-8 read as %s: %s",
+					strings.Join(steps, ", then as "),
+					string(preview),
+				))
+			}
+			if len(fixes) > 0 {
+				list.SetCurrentRow(1)
+			}
+		})
+	}()
+}
+
+func updateMojibake_Points(text string) {
+	lines := []string{}
+	for idx, r := range []rune(text) {
+		if idx >= 512 {
+			lines = append(lines, "…")
+			break
+		}
+
+		glyph := "&nbsp;"
+		if runeSupported(r) && r > 0x20 {
+			glyph = html.EscapeString(string(r))
+		}
+		lines = append(lines, fmt.Sprintf(
+			"<a href=\"%d\">U+%04X</a>&nbsp;&nbsp;%s&nbsp;&nbsp;%s",
+			r, r, glyph, html.EscapeString(runeName(r)),
+		))
+	}
+	moji_PointList.SetText(strings.Join(lines, "<br>"))
+}
@@ -0,0 +1,170 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+type Subsection struct {
+	Name   string
+	Points []rune
+}
+
+var (
+	navPanel  *qt6.QDockWidget
+	navTree   *qt6.QTreeWidget
+	navBars   []*qt6.QProgressBar
+	navSubs   [][]Subsection
+	navCancel context.CancelFunc
+)
+
+func MakeNavigator() *qt6.QDockWidget {
+	navPanel = qt6.NewQDockWidget2("Blocks")
+	navPanel.SetAllowedAreas(
+		qt6.BottomDockWidgetArea |
+			qt6.RightDockWidgetArea |
+			qt6.LeftDockWidgetArea,
+	)
+
+	navTree = qt6.NewQTreeWidget2()
+	navTree.SetColumnCount(3)
+	navTree.SetHeaderLabels([]string{"Block", "Range", "Coverage"})
+	navTree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		onLink(fmt.Sprint(item.Data(0, int(qt6.UserRole)).ToInt()))
+	})
+	navPanel.SetWidget(navTree.QWidget)
+
+	return navPanel
+}
+
+// Groups the NamesList entries of every block by their subheader
+func navigator_Subsections() [][]Subsection {
+	ret := make([][]Subsection, len(blocks)-1)
+	points := []rune{}
+	namesMut.Lock()
+	for _, node := range names {
+		if node.Raw != "<Undefined>" {
+			points = append(points, node.Point)
+		}
+	}
+	slices.Sort(points)
+
+	idx := 0
+	for _, r := range points {
+		for idx < len(ret) && blocks[idx].End < r {
+			idx++
+		}
+		if idx >= len(ret) || r < blocks[idx].Start {
+			continue
+		}
+
+		subhead := names[fmt.Sprintf("%04X", r)].Subhead
+		subs := ret[idx]
+		if len(subs) == 0 || subs[len(subs)-1].Name != subhead {
+			subs = append(subs, Subsection{Name: subhead})
+		}
+		last := &subs[len(subs)-1]
+		last.Points = append(last.Points, r)
+		ret[idx] = subs
+	}
+	namesMut.Unlock()
+	return ret
+}
+
+func buildNavigator() {
+	navTree.Clear()
+	navSubs = navigator_Subsections()
+	navBars = make([]*qt6.QProgressBar, len(navSubs))
+
+	for idx, block := range blocks[:len(blocks)-1] {
+		item := qt6.NewQTreeWidgetItem()
+		item.SetText(0, block.Name)
+		item.SetText(1, fmt.Sprintf("%04X..%04X", block.Start, block.End))
+		item.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(int(block.Start)))
+		item.SetFont(1, monoFont)
+
+		for _, sub := range navSubs[idx] {
+			if sub.Name == "" {
+				continue
+			}
+			child := qt6.NewQTreeWidgetItem()
+			child.SetText(0, sub.Name)
+			child.SetText(1, fmt.Sprintf("%04X..%04X", sub.Points[0], sub.Points[len(sub.Points)-1]))
+			child.SetData(0, int(qt6.UserRole), qt6.NewQVariant4(int(sub.Points[0])))
+			child.SetFont(1, monoFont)
+			item.AddChild(child)
+		}
+		navTree.AddTopLevelItem(item)
+
+		bar := qt6.NewQProgressBar2()
+		bar.SetRange(0, 1000)
+		bar.SetValue(0)
+		bar.SetMaximumHeight(navTree.FontMetrics().Height())
+		navTree.SetItemWidget(item, 2, bar.QWidget)
+		navBars[idx] = bar
+	}
+
+	navTree.ResizeColumnToContents(0)
+	navTree.ResizeColumnToContents(1)
+	updateNavigator()
+}
+
+func updateNavigator() {
+	if navBars == nil {
+		return
+	}
+	if navCancel != nil {
+		navCancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	navCancel = cancel
+	fam := fontKey(fontPair.Raw)
+	cmap := currentCmap()
+
+	go func() {
+		rows := blockCoverage(ctx, fam, cmap, nil)
+		if rows == nil {
+			return
+		}
+
+		subs := make([][]float64, len(navSubs))
+		for idx, list := range navSubs {
+			for _, sub := range list {
+				n := 0
+				for _, r := range sub.Points {
+					if cmap[r] != 0 {
+						n++
+					}
+				}
+				subs[idx] = append(subs[idx], 100*float64(n)/float64(len(sub.Points)))
+			}
+		}
+
+		mainthread.Wait(func() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			for idx, cov := range rows {
+				navBars[idx].SetValue(int(cov.Percent() * 10))
+				navBars[idx].SetFormat(fmt.Sprintf("%.0f%%", cov.Percent()))
+				navBars[idx].SetToolTip(fmt.Sprintf("%d of %d", cov.Supported, cov.Assigned))
+
+				item := navTree.TopLevelItem(idx)
+				child := 0
+				for sub, pct := range subs[idx] {
+					if navSubs[idx][sub].Name == "" {
+						continue
+					}
+					item.Child(child).SetText(2, fmt.Sprintf("%.0f%%", pct))
+					child++
+				}
+			}
+		})
+	}()
+}
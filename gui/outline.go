@@ -0,0 +1,370 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"fontview/tables"
+	"math"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+type outlineGlyph struct {
+	gid    uint
+	path   *qt6.QPainterPath
+	points []sfnt.OutlinePoint
+	ends   []int
+
+	advance, lsb, rsb      float64
+	xMin, yMin, xMax, yMax float64
+	ascender, descender    float64
+	xHeight, capHeight     float64
+}
+
+var (
+	outline_Dialog   *qt6.QDialog
+	outline_Summary  *qt6.QLabel
+	outline_Canvas   *qt6.QWidget
+	outline_Readouts *qt6.QTreeWidget
+	outline_Points   *qt6.QCheckBox
+	outline_Metrics  *qt6.QCheckBox
+
+	outline_Cur *outlineGlyph
+)
+
+func showOutline() {
+	if outline_Dialog == nil {
+		makeOutline()
+	}
+	outline_Dialog.Show()
+	outline_Dialog.Raise()
+	outline_Dialog.ActivateWindow()
+	updateOutline_Glyph(curNode)
+}
+
+func makeOutline() {
+	outline_Dialog = qt6.NewQDialog(window.QWidget)
+	outline_Dialog.SetWindowTitle("Outline Inspector")
+	outline_Dialog.Resize(900, 640)
+	layout := qt6.NewQVBoxLayout(outline_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+	outline_Summary = qt6.NewQLabel2()
+	outline_Points = qt6.NewQCheckBox3("Points")
+	outline_Points.SetChecked(true)
+	outline_Points.OnToggled(func(_ bool) { outline_Canvas.Update() })
+	outline_Metrics = qt6.NewQCheckBox3("Metrics")
+	outline_Metrics.SetChecked(true)
+	outline_Metrics.OnToggled(func(_ bool) { outline_Canvas.Update() })
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("Read the outline from the current font")
+	btnRefresh.OnClicked(func() { updateOutline_Glyph(curNode) })
+	headLayout.AddWidget2(outline_Summary.QWidget, 1)
+	headLayout.AddWidget(outline_Points.QWidget)
+	headLayout.AddWidget(outline_Metrics.QWidget)
+	headLayout.AddWidget(btnRefresh.QWidget)
+
+	bodyWidget := qt6.NewQWidget2()
+	bodyLayout := qt6.NewQHBoxLayout(bodyWidget)
+	bodyLayout.SetContentsMargins(0, 0, 0, 0)
+	outline_Canvas = qt6.NewQWidget2()
+	outline_Canvas.SetMinimumSize2(320, 320)
+	outline_Canvas.OnPaintEvent(func(super func(evt *qt6.QPaintEvent), evt *qt6.QPaintEvent) {
+		painter := qt6.NewQPainter2(outline_Canvas.QPaintDevice)
+		painter.SetRenderHint(qt6.QPainter__Antialiasing)
+		paintOutline(painter, float64(outline_Canvas.Width()), float64(outline_Canvas.Height()))
+		painter.End()
+	})
+	outline_Readouts = qt6.NewQTreeWidget2()
+	outline_Readouts.SetColumnCount(2)
+	outline_Readouts.SetHeaderHidden(true)
+	outline_Readouts.SetRootIsDecorated(false)
+	outline_Readouts.SetFixedWidth(260)
+	bodyLayout.AddWidget2(outline_Canvas, 1)
+	bodyLayout.AddWidget(outline_Readouts.QWidget)
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(bodyWidget, 1)
+}
+
+// Points from the path Qt builds, for outlines that aren't in glyf. Cubic
+// control points are the real off-curve points of CFF fonts.
+func outline_PathPoints(path *qt6.QPainterPath) ([]sfnt.OutlinePoint, []int) {
+	points, ends := []sfnt.OutlinePoint{}, []int{}
+	start, curve := 0, 0
+	closeContour := func() {
+		if len(points) > start+1 {
+			first, last := points[start], points[len(points)-1]
+			// Qt closes subpaths with a line back to the start
+			if first.X == last.X && first.Y == last.Y {
+				points = points[:len(points)-1]
+			}
+		}
+		if len(points) > start {
+			ends = append(ends, len(points)-1)
+		}
+		start = len(points)
+	}
+
+	for idx := range path.ElementCount() {
+		el := path.ElementAt(idx)
+		pt := el.ToQPointF()
+		point := sfnt.OutlinePoint{X: pt.X(), Y: -pt.Y(), OnCurve: true}
+		switch {
+		case el.IsMoveTo():
+			closeContour()
+		case el.IsCurveTo():
+			point.OnCurve, curve = false, 1
+		case !el.IsLineTo() && curve == 1:
+			point.OnCurve, curve = false, 0
+		}
+		points = append(points, point)
+	}
+	closeContour()
+	return points, ends
+}
+
+// Positive for counter-clockwise contours, TrueType draws outer contours
+// clockwise and CFF counter-clockwise
+func outline_Area(points []sfnt.OutlinePoint) float64 {
+	area := 0.0
+	for idx, pt := range points {
+		next := points[(idx+1)%len(points)]
+		area += pt.X*next.Y - next.X*pt.Y
+	}
+	return area / 2
+}
+
+// Reads the outline of the selected glyph. Called by updateInfo, so does
+// nothing while the dialog is closed.
+func updateOutline_Glyph(node tables.Node) {
+	if outline_Dialog == nil || !outline_Dialog.IsVisible() {
+		return
+	}
+
+	outline_Cur = nil
+	outline_Readouts.Clear()
+	defer outline_Canvas.Update()
+
+	fam := fontKey(fontPair.Raw)
+	raw := qt6.NewQRawFont4(fontPair.Raw)
+	upem := raw.UnitsPerEm()
+	// At one pixel per unit the path is in font units
+	raw.SetPixelSize(upem)
+	gids := raw.GlyphIndexesForString(string(node.Point))
+	if len(gids) == 0 || gids[0] == 0 {
+		outline_Summary.SetText(fmt.Sprintf("<b>%s</b>: U+%s is not in the font", fam, node.Code))
+		return
+	}
+
+	g := &outlineGlyph{gid: gids[0]}
+	g.path = raw.PathForGlyph(g.gid)
+	g.advance = raw.AdvancesForGlyphIndexes([]uint{g.gid})[0].X()
+	rect := g.path.BoundingRect()
+	if !g.path.IsEmpty() {
+		g.xMin, g.xMax = rect.Left(), rect.Right()
+		g.yMin, g.yMax = -rect.Bottom(), -rect.Top()
+		g.lsb, g.rsb = g.xMin, g.advance-g.xMax
+	}
+	g.ascender, g.descender = raw.Ascent(), -raw.Descent()
+	g.xHeight, g.capHeight = raw.XHeight(), raw.CapHeight()
+
+	font := fontPair.Sfnt
+	source := "glyf"
+	g.points, g.ends = font.GlyphPoints(uint16(g.gid))
+	if len(g.points) == 0 {
+		source = "path"
+		g.points, g.ends = outline_PathPoints(g.path)
+	}
+	if hhea, err := font.Hhea(); err == nil {
+		g.ascender, g.descender = float64(hhea.Ascender), float64(hhea.Descender)
+	}
+	if os2, err := font.OS2(); err == nil && os2.Version >= 2 {
+		g.xHeight, g.capHeight = float64(os2.XHeight), float64(os2.CapHeight)
+	}
+	outline_Cur = g
+
+	on := 0
+	for _, pt := range g.points {
+		if pt.OnCurve {
+			on++
+		}
+	}
+	outline_Summary.SetText(fmt.Sprintf(
+		"<b>%s</b>: U+%s, glyph %d, %d contours, %d points (%s)",
+		fam, node.Code, g.gid, len(g.ends), len(g.points), source,
+	))
+
+	row := func(key, value string) {
+		item := qt6.NewQTreeWidgetItem()
+		item.SetText(0, key)
+		item.SetText(1, value)
+		outline_Readouts.AddTopLevelItem(item)
+	}
+	names := font.GlyphNames()
+	if int(g.gid) < len(names) && names[g.gid] != "" {
+		row("Glyph Name", names[g.gid])
+	}
+	row("Units per Em", fmt.Sprint(upem))
+	row("Advance Width", fmt.Sprintf("%g", g.advance))
+	row("Left Bearing", fmt.Sprintf("%g", g.lsb))
+	row("Right Bearing", fmt.Sprintf("%g", g.rsb))
+	row("Bounds", fmt.Sprintf("%g, %g to %g, %g", g.xMin, g.yMin, g.xMax, g.yMax))
+	row("On-curve Points", fmt.Sprint(on))
+	row("Off-curve Points", fmt.Sprint(len(g.points)-on))
+	start := 0
+	for idx, end := range g.ends {
+		direction := "clockwise"
+		if outline_Area(g.points[start:end+1]) > 0 {
+			direction = "counter-clockwise"
+		}
+		row(fmt.Sprintf("Contour %d", idx), fmt.Sprintf("%d points, %s", end+1-start, direction))
+		start = end + 1
+	}
+	row("Ascender", fmt.Sprintf("%g", g.ascender))
+	row("Cap Height", fmt.Sprintf("%g", g.capHeight))
+	row("x-Height", fmt.Sprintf("%g", g.xHeight))
+	row("Descender", fmt.Sprintf("%g", g.descender))
+	outline_Readouts.ResizeColumnToContents(0)
+}
+
+func paintOutline(painter *qt6.QPainter, w, h float64) {
+	g := outline_Cur
+	if g == nil {
+		return
+	}
+
+	left, right := min(0, g.xMin), max(g.advance, g.xMax, 1)
+	top, bottom := max(g.ascender, g.capHeight, g.yMax), min(g.descender, g.yMin)
+	margin := 40.0
+	scale := min((w-2*margin)/(right-left), (h-2*margin)/(top-bottom))
+	if scale <= 0 || top <= bottom {
+		return
+	}
+	ox := (w-(right-left)*scale)/2 - left*scale
+	oy := (h-(top-bottom)*scale)/2 + top*scale
+	at := func(x, y float64) *qt6.QPointF {
+		return qt6.NewQPointF3(ox+x*scale, oy-y*scale)
+	}
+	pen := func(color string, style qt6.PenStyle) *qt6.QPen {
+		ret := qt6.NewQPen6(qt6.NewQBrush3(qt6.NewQColor6(color)), 1, style)
+		ret.SetCosmetic(true)
+		return ret
+	}
+
+	if outline_Metrics.IsChecked() {
+		lines := []struct {
+			name  string
+			value float64
+			color string
+		}{
+			{"Ascender", g.ascender, sakurapine.Paint.Foam},
+			{"Cap Height", g.capHeight, sakurapine.Paint.Pine},
+			{"x-Height", g.xHeight, sakurapine.Paint.Pine},
+			{"Baseline", 0, sakurapine.Text.Normal},
+			{"Descender", g.descender, sakurapine.Paint.Foam},
+		}
+		for _, line := range lines {
+			if line.value == 0 && line.name != "Baseline" {
+				continue
+			}
+			y := oy - line.value*scale
+			painter.SetPenWithPen(pen(line.color, qt6.DashLine))
+			painter.DrawLine(qt6.NewQLineF3(0, y, w, y))
+			painter.DrawText(qt6.NewQPointF3(4, y-3), fmt.Sprintf("%s %g", line.name, line.value))
+		}
+
+		painter.SetPenWithPen(pen(sakurapine.Text.Muted, qt6.DashLine))
+		for _, x := range []float64{0, g.advance} {
+			painter.DrawLine(qt6.NewQLineF3(ox+x*scale, 0, ox+x*scale, h))
+		}
+		painter.DrawText(qt6.NewQPointF3(ox+g.advance*scale+4, 14), fmt.Sprintf("Advance %g", g.advance))
+
+		// Side bearings as dimension lines below the descender
+		if !g.path.IsEmpty() {
+			y := oy - bottom*scale + 16
+			painter.SetPenWithPen(pen(sakurapine.Paint.Gold, qt6.SolidLine))
+			for _, span := range [][3]float64{{0, g.xMin, g.lsb}, {g.xMax, g.advance, g.rsb}} {
+				x1, x2 := ox+span[0]*scale, ox+span[1]*scale
+				painter.DrawLine(qt6.NewQLineF3(x1, y, x2, y))
+				painter.DrawLine(qt6.NewQLineF3(x1, y-4, x1, y+4))
+				painter.DrawLine(qt6.NewQLineF3(x2, y-4, x2, y+4))
+				painter.DrawText(qt6.NewQPointF3(min(x1, x2), y+16), fmt.Sprintf("%g", span[2]))
+			}
+		}
+	}
+
+	painter.Save()
+	painter.Translate2(ox, oy)
+	painter.Scale(scale, scale)
+	fill := qt6.NewQColor6(sakurapine.Text.Normal)
+	fill.SetAlphaF(0.15)
+	painter.FillPath(g.path, qt6.NewQBrush3(fill))
+	painter.StrokePath(g.path, pen(sakurapine.Text.Normal, qt6.SolidLine))
+	painter.Restore()
+
+	if !outline_Points.IsChecked() {
+		return
+	}
+
+	start := 0
+	for _, end := range g.ends {
+		if end >= len(g.points) {
+			break
+		}
+		contour := g.points[start : end+1]
+		start = end + 1
+
+		// Handles from off-curve points to their neighbours
+		painter.SetPenWithPen(pen(sakurapine.Text.Muted, qt6.DotLine))
+		for idx, pt := range contour {
+			if pt.OnCurve {
+				continue
+			}
+			for _, other := range []sfnt.OutlinePoint{
+				contour[(idx+len(contour)-1)%len(contour)],
+				contour[(idx+1)%len(contour)],
+			} {
+				painter.DrawLine4(at(pt.X, pt.Y), at(other.X, other.Y))
+			}
+		}
+
+		for _, pt := range contour {
+			if pt.OnCurve {
+				painter.SetPenWithPen(pen(sakurapine.Paint.Iris, qt6.SolidLine))
+				painter.SetBrush(qt6.NewQBrush3(qt6.NewQColor6(sakurapine.Paint.Iris)))
+				painter.DrawEllipse3(at(pt.X, pt.Y), 3.5, 3.5)
+			} else {
+				painter.SetPenWithPen(pen(sakurapine.Paint.Love, qt6.SolidLine))
+				painter.SetBrushWithStyle(qt6.NoBrush)
+				painter.DrawEllipse3(at(pt.X, pt.Y), 3, 3)
+			}
+		}
+
+		// Start point and an arrow along the contour direction
+		if len(contour) < 2 {
+			continue
+		}
+		first, second := at(contour[0].X, contour[0].Y), at(contour[1].X, contour[1].Y)
+		dx, dy := second.X()-first.X(), second.Y()-first.Y()
+		length := math.Hypot(dx, dy)
+		painter.SetPenWithPen(pen(sakurapine.Paint.Gold, qt6.SolidLine))
+		painter.SetBrushWithStyle(qt6.NoBrush)
+		painter.DrawEllipse3(first, 7, 7)
+		if length == 0 {
+			continue
+		}
+		dx, dy = dx/length, dy/length
+		tip := qt6.NewQPointF3(first.X()+dx*26, first.Y()+dy*26)
+		painter.DrawLine4(qt6.NewQPointF3(first.X()+dx*8, first.Y()+dy*8), tip)
+		for _, angle := range []float64{2.6, -2.6} {
+			sin, cos := math.Sincos(angle)
+			painter.DrawLine4(tip, qt6.NewQPointF3(
+				tip.X()+7*(dx*cos-dy*sin), tip.Y()+7*(dx*sin+dy*cos),
+			))
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"fontview/tables"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+type FontRank struct {
+	Family  string
+	Missing []rune
+	Total   int
+}
+
+func (r FontRank) Percent() float64 {
+	if r.Total == 0 {
+		return 100
+	}
+	return 100 * float64(r.Total-len(r.Missing)) / float64(r.Total)
+}
+
+var (
+	rank_Tree     *qt6.QTreeWidget
+	rank_Progress *qt6.QProgressBar
+	rank_Start    *qt6.QPushButton
+	rank_Cancel   *qt6.QPushButton
+	rank_Stop     context.CancelFunc
+)
+
+func makeRank() *qt6.QWidget {
+	rankWidget := qt6.NewQWidget2()
+	rankLayout := qt6.NewQVBoxLayout(rankWidget)
+	rankLayout.SetContentsMargins(0, 0, 0, 0)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	rank_Start = qt6.NewQPushButton3("Rank installed fonts")
+	rank_Start.SetToolTip("Ranks the selected text, or the whole corpus if nothing is selected")
+	rank_Start.OnClicked(rank_StartEvt)
+	rank_Cancel = qt6.NewQPushButton3("Cancel")
+	rank_Cancel.SetDisabled(true)
+	rank_Cancel.OnClicked(func() {
+		if rank_Stop != nil {
+			rank_Stop()
+		}
+	})
+	rank_Progress = qt6.NewQProgressBar2()
+	rank_Progress.SetRange(0, 1)
+	rank_Progress.SetValue(0)
+
+	headLayout.AddWidget(rank_Start.QWidget)
+	headLayout.AddWidget2(rank_Progress.QWidget, 1)
+	headLayout.AddWidget(rank_Cancel.QWidget)
+
+	rank_Tree = qt6.NewQTreeWidget2()
+	rank_Tree.SetColumnCount(3)
+	rank_Tree.SetHeaderLabels([]string{"Family", "Coverage", "Missing"})
+	rank_Tree.SetToolTip("Double click a family to load it")
+	rank_Tree.OnItemClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		if item.Parent() == nil {
+			return
+		}
+
+		code := strings.Fields(item.Text(0))
+		point, err := strconv.ParseInt(strings.TrimPrefix(code[0], "U+"), 16, 64)
+		if err != nil {
+			return
+		}
+		onLink(fmt.Sprint(point))
+	})
+	rank_Tree.OnItemDoubleClicked(func(item *qt6.QTreeWidgetItem, _ int) {
+		if item.Parent() != nil {
+			return
+		}
+		fontBox.SetCurrentFont(qt6.NewQFont2(item.Text(0)))
+	})
+
+	rankLayout.AddWidget(headWidget)
+	rankLayout.AddWidget2(rank_Tree.QWidget, 1)
+	return rankWidget
+}
+
+func rank_StartEvt() {
+	text := cov_Input.TextCursor().SelectedText()
+	if text == "" {
+		text = cov_Input.ToPlainText()
+	}
+	// QTextCursor uses the paragraph separator in place of new lines
+	text = strings.ReplaceAll(text, "\u2029", "\n")
+	points := tables.NewCorpus(text).Points
+	families := qt6.QFontDatabase_Families()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rank_Stop = cancel
+	rank_Start.SetDisabled(true)
+	rank_Cancel.SetDisabled(false)
+	rank_Progress.SetRange(0, len(families))
+	rank_Progress.SetValue(0)
+	rank_Tree.Clear()
+
+	go func() {
+		ranks := rankFonts(ctx, families, points, func(done int) {
+			mainthread.Start(func() { rank_Progress.SetValue(done) })
+		})
+		mainthread.Wait(func() {
+			updateRank(ranks)
+			rank_Start.SetDisabled(false)
+			rank_Cancel.SetDisabled(true)
+		})
+		cancel()
+	}()
+}
+
+func rankFonts(ctx context.Context, families []string, points []rune, progress func(int)) []FontRank {
+	queue := make(chan string)
+	ret := []FontRank{}
+	retMut := sync.Mutex{}
+	done := atomic.Int64{}
+	wg := sync.WaitGroup{}
+
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fam := range queue {
+				rank := FontRank{Family: fam, Total: len(points)}
+				for _, r := range points {
+					if ctx.Err() != nil {
+						return
+					}
+					if !familySupports(fam, r) {
+						rank.Missing = append(rank.Missing, r)
+					}
+				}
+
+				retMut.Lock()
+				ret = append(ret, rank)
+				retMut.Unlock()
+				progress(int(done.Add(1)))
+			}
+		}()
+	}
+
+send:
+	for _, fam := range families {
+		select {
+		case queue <- fam:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	slices.SortFunc(ret, func(a, b FontRank) int {
+		if len(a.Missing) != len(b.Missing) {
+			return len(a.Missing) - len(b.Missing)
+		}
+		return strings.Compare(a.Family, b.Family)
+	})
+	return ret
+}
+
+func updateRank(ranks []FontRank) {
+	rank_Tree.Clear()
+
+	for _, rank := range ranks {
+		parent := qt6.NewQTreeWidgetItem()
+		parent.SetText(0, rank.Family)
+		parent.SetText(1, fmt.Sprintf("%.1f%%", rank.Percent()))
+		parent.SetText(2, fmt.Sprint(len(rank.Missing)))
+
+		for idx, r := range rank.Missing {
+			if idx >= 256 {
+				child := qt6.NewQTreeWidgetItem()
+				child.SetText(0, fmt.Sprintf("... %d more", len(rank.Missing)-idx))
+				parent.AddChild(child)
+				break
+			}
+
+			child := qt6.NewQTreeWidgetItem()
+			child.SetText(0, fmt.Sprintf("U+%04X", r))
+			child.SetText(2, runeName(r))
+			parent.AddChild(child)
+		}
+		rank_Tree.AddTopLevelItem(parent)
+	}
+	rank_Tree.ResizeColumnToContents(0)
+}
@@ -0,0 +1,76 @@
+package gui
+
+import (
+	"github.com/mappu/miqt/qt6"
+)
+
+// Factor from the pixel size of `raw` to a line `size` pixels high, leaving
+// a small margin around the ascent and descent
+func glyphScale(raw *qt6.QRawFont, size int) float64 {
+	height := raw.Ascent() + raw.Descent()
+	if height <= 0 {
+		return 0
+	}
+	return float64(size) * 0.8 / height
+}
+
+// Draws a glyph by ID onto a transparent square pixmap, centred on its
+// advance width. Works for glyphs no code point maps to.
+func glyphPixmap(raw *qt6.QRawFont, gid uint, size int, color string) *qt6.QPixmap {
+	pix := qt6.NewQPixmap2(size, size)
+	pix.FillWithFillColor(qt6.NewQColor2(qt6.Transparent))
+
+	scale := glyphScale(raw, size)
+	if scale == 0 {
+		return pix
+	}
+	advance := raw.AdvancesForGlyphIndexes([]uint{gid})[0].X()
+	height := raw.Ascent() + raw.Descent()
+
+	painter := qt6.NewQPainter2(pix.QPaintDevice)
+	painter.SetRenderHint(qt6.QPainter__Antialiasing)
+	painter.Translate2(
+		(float64(size)-advance*scale)/2,
+		(float64(size)-height*scale)/2+raw.Ascent()*scale,
+	)
+	painter.Scale(scale, scale)
+	painter.FillPath(raw.PathForGlyph(gid), qt6.NewQBrush3(qt6.NewQColor6(color)))
+	painter.End()
+	return pix
+}
+
+// Draws a run of glyphs by ID side by side on one line `size` pixels high,
+// for text that has been shaped without Qt. `kern` optionally adjusts the
+// advance of each glyph, in the pixel size of `raw`.
+func glyphRunPixmap(raw *qt6.QRawFont, gids []uint, kern []float64, size int, color string) *qt6.QPixmap {
+	scale := glyphScale(raw, size)
+	advances := make([]float64, len(gids))
+	width := 0.0
+	for idx, adv := range raw.AdvancesForGlyphIndexes(gids) {
+		advances[idx] = adv.X()
+		if idx < len(kern) {
+			advances[idx] += kern[idx]
+		}
+		width += advances[idx]
+	}
+
+	margin := float64(size) * 0.1
+	pix := qt6.NewQPixmap2(max(int(width*scale+2*margin), 1), size)
+	pix.FillWithFillColor(qt6.NewQColor2(qt6.Transparent))
+	if scale == 0 {
+		return pix
+	}
+
+	height := raw.Ascent() + raw.Descent()
+	painter := qt6.NewQPainter2(pix.QPaintDevice)
+	painter.SetRenderHint(qt6.QPainter__Antialiasing)
+	painter.Translate2(margin, (float64(size)-height*scale)/2+raw.Ascent()*scale)
+	painter.Scale(scale, scale)
+	brush := qt6.NewQBrush3(qt6.NewQColor6(color))
+	for idx, gid := range gids {
+		painter.FillPath(raw.PathForGlyph(gid), brush)
+		painter.Translate2(advances[idx], 0)
+	}
+	painter.End()
+	return pix
+}
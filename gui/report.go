@@ -0,0 +1,259 @@
+package gui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"fontview/tables"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	report_Dialog   *qt6.QDialog
+	report_Table    *qt6.QTableWidget
+	report_Summary  *qt6.QLabel
+	report_Progress *qt6.QProgressBar
+	report_Rows     []tables.Coverage
+	report_Family   string
+
+	assignedSet   map[rune]bool
+	assignedMut   sync.Mutex
+	coverageCache = map[string][]tables.Coverage{}
+	coverageMut   sync.Mutex
+)
+
+// NamesList leaves out the algorithmically named code points, eg CJK
+// ideographs and Hangul syllables, so anything the unicode package puts in a
+// category counts as assigned too.
+func runeAssigned(r rune) bool {
+	assignedMut.Lock()
+	if assignedSet == nil {
+		assignedSet = map[rune]bool{}
+		namesMut.Lock()
+		for _, node := range names {
+			if node.Raw != "<Undefined>" {
+				assignedSet[node.Point] = true
+			}
+		}
+		namesMut.Unlock()
+	}
+	ok := assignedSet[r]
+	assignedMut.Unlock()
+
+	if ok {
+		return true
+	}
+	cat := tables.GeneralCategory(r)
+	return cat != "Cn" && cat != "Cs"
+}
+
+// Returns nil if `ctx` is cancelled before every block is counted, or if the
+// blocks haven't loaded yet
+func blockCoverage(ctx context.Context, fam string, cmap map[rune]uint16, progress func(done, total int)) []tables.Coverage {
+	coverageMut.Lock()
+	cached, ok := coverageCache[fam]
+	coverageMut.Unlock()
+	if ok {
+		return cached
+	}
+
+	blocksMut.Lock()
+	if len(blocks) == 0 {
+		blocksMut.Unlock()
+		return nil
+	}
+	// The last block is the catch-all "Other" spanning every code point
+	todo := blocks[:len(blocks)-1]
+	blocksMut.Unlock()
+
+	ret := make([]tables.Coverage, len(todo))
+	for idx, block := range todo {
+		row := tables.Coverage{
+			Block: block.Name,
+			Start: block.Start,
+			End:   block.End,
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		for r := block.Start; r <= block.End; r++ {
+			if !runeAssigned(r) {
+				continue
+			}
+			row.Assigned++
+			if cmap[r] != 0 {
+				row.Supported++
+			}
+		}
+		ret[idx] = row
+		if progress != nil {
+			progress(idx+1, len(todo))
+		}
+	}
+
+	coverageMut.Lock()
+	coverageCache[fam] = ret
+	coverageMut.Unlock()
+	return ret
+}
+
+func showReport() {
+	if report_Dialog == nil {
+		makeReport()
+	}
+	report_Dialog.Show()
+	report_Dialog.Raise()
+	report_Dialog.ActivateWindow()
+	updateReport()
+}
+
+func makeReport() {
+	report_Dialog = qt6.NewQDialog(window.QWidget)
+	report_Dialog.SetWindowTitle("Coverage Report")
+	report_Dialog.Resize(720, 640)
+	layout := qt6.NewQVBoxLayout(report_Dialog.QWidget)
+
+	headWidget := qt6.NewQWidget2()
+	headLayout := qt6.NewQHBoxLayout(headWidget)
+	headLayout.SetContentsMargins(0, 0, 0, 0)
+
+	report_Summary = qt6.NewQLabel2()
+	report_Progress = qt6.NewQProgressBar2()
+	btnRefresh := qt6.NewQPushButton2()
+	btnRefresh.SetIcon(icons["view-refresh"])
+	btnRefresh.SetToolTip("Report on the current font")
+	btnRefresh.OnClicked(updateReport)
+	btnExport := qt6.NewQPushButton3("Export...")
+	btnExport.OnClicked(report_ExportEvt)
+
+	headLayout.AddWidget2(report_Summary.QWidget, 1)
+	headLayout.AddWidget(report_Progress.QWidget)
+	headLayout.AddWidget(btnRefresh.QWidget)
+	headLayout.AddWidget(btnExport.QWidget)
+
+	report_Table = qt6.NewQTableWidget2()
+	report_Table.SetColumnCount(5)
+	report_Table.SetHorizontalHeaderLabels([]string{
+		"Block", "Range", "Supported", "Assigned", "Coverage",
+	})
+	report_Table.HorizontalHeader().SetStretchLastSection(true)
+	report_Table.VerticalHeader().SetVisible(false)
+	report_Table.SetEditTriggers(qt6.QAbstractItemView__NoEditTriggers)
+	report_Table.SetSelectionBehavior(qt6.QAbstractItemView__SelectRows)
+	report_Table.OnCellDoubleClicked(func(row, _ int) {
+		item := report_Table.Item(row, 1)
+		if item == nil {
+			return
+		}
+		for _, cov := range report_Rows {
+			if cov.Range() == item.Text() {
+				onLink(fmt.Sprint(cov.Start))
+				return
+			}
+		}
+	})
+
+	layout.AddWidget(headWidget)
+	layout.AddWidget2(report_Table.QWidget, 1)
+}
+
+func updateReport() {
+	fam := fontKey(fontPair.Raw)
+	cmap := currentCmap()
+	report_Summary.SetText(fmt.Sprintf("<b>%s</b>: counting...", fam))
+	report_Progress.SetVisible(true)
+
+	go func() {
+		rows := blockCoverage(context.Background(), fam, cmap, func(done, total int) {
+			mainthread.Start(func() {
+				report_Progress.SetRange(0, total)
+				report_Progress.SetValue(done)
+			})
+		})
+		mainthread.Wait(func() {
+			report_Progress.SetVisible(false)
+			if rows == nil {
+				report_Summary.SetText(fmt.Sprintf("<b>%s</b>: Unicode blocks are still loading", fam))
+				return
+			}
+			report_Family = fam
+			report_Rows = rows
+			updateReport_Table()
+		})
+	}()
+}
+
+func updateReport_Table() {
+	report_Table.SetSortingEnabled(false)
+	report_Table.SetRowCount(0)
+
+	supported, assigned := 0, 0
+	for _, cov := range report_Rows {
+		supported += cov.Supported
+		assigned += cov.Assigned
+
+		row := report_Table.RowCount()
+		report_Table.InsertRow(row)
+		report_Table.SetItem(row, 0, qt6.NewQTableWidgetItem2(cov.Block))
+		report_Table.SetItem(row, 1, qt6.NewQTableWidgetItem2(cov.Range()))
+		report_Table.SetItem(row, 2, report_Number(qt6.NewQVariant4(cov.Supported)))
+		report_Table.SetItem(row, 3, report_Number(qt6.NewQVariant4(cov.Assigned)))
+		report_Table.SetItem(row, 4, report_Number(qt6.NewQVariant9(cov.Percent())))
+
+		bar := qt6.NewQProgressBar2()
+		bar.SetRange(0, 1000)
+		bar.SetValue(int(cov.Percent() * 10))
+		bar.SetFormat(fmt.Sprintf("%.1f%%", cov.Percent()))
+		report_Table.SetCellWidget(row, 4, bar.QWidget)
+	}
+
+	report_Table.ResizeColumnToContents(0)
+	report_Table.ResizeColumnToContents(1)
+	report_Table.SetSortingEnabled(true)
+	report_Summary.SetText(fmt.Sprintf(
+		"<b>%s</b>: %d of %d assigned code points in %d blocks",
+		report_Family, supported, assigned, report_Table.RowCount(),
+	))
+}
+
+func report_Number(value *qt6.QVariant) *qt6.QTableWidgetItem {
+	item := qt6.NewQTableWidgetItem()
+	item.SetData(int(qt6.DisplayRole), value)
+	item.SetTextAlignment2(qt6.AlignRight | qt6.AlignVCenter)
+	return item
+}
+
+func report_ExportEvt() {
+	name := strings.ReplaceAll(report_Family, " ", "_") + "-coverage"
+	path := qt6.QFileDialog_GetSaveFileName4(
+		report_Dialog.QWidget, "Export report", name+".csv",
+		"CSV (*.csv);;JSON (*.json);;Markdown (*.md)",
+	)
+	if path == "" {
+		return
+	}
+
+	buf := bytes.Buffer{}
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = tables.WriteCoverageJSON(&buf, report_Family, report_Rows)
+	case ".md", ".markdown":
+		err = tables.WriteCoverageMarkdown(&buf, report_Family, report_Rows)
+	default:
+		err = tables.WriteCoverageCSV(&buf, report_Family, report_Rows)
+	}
+	if err == nil {
+		err = os.WriteFile(path, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		qt6.QMessageBox_Critical(report_Dialog.QWidget, "Export failed", err.Error())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"fontview/sakura"
+	"fontview/sfnt"
 
 	"github.com/mappu/miqt/qt6"
 	"github.com/mappu/miqt/qt6/mainthread"
@@ -13,6 +14,9 @@ import (
 type FontPair struct {
 	Raw  *qt6.QRawFont
 	Real *qt6.QFont
+	Sfnt *sfnt.Font
+	// Styles Qt fakes on top of the face, eg "bold"
+	Synthetic []string
 }
 
 var (
@@ -53,6 +57,11 @@ func Launch() {
 	layout.AddWidget(MakeHead())
 	layout.AddWidget(MakeTable())
 	window.AddDockWidget(qt6.RightDockWidgetArea, MakeInfo())
+	window.AddDockWidget(qt6.BottomDockWidgetArea, MakeInspector())
+	window.AddDockWidget(qt6.LeftDockWidgetArea, MakeNavigator())
+	window.AddDockWidget(qt6.LeftDockWidgetArea, MakeVariations())
+	window.AddDockWidget(qt6.BottomDockWidgetArea, MakeCompare())
+	MakeMenu()
 
 	window.OnShowEvent(func(_ func(_ *qt6.QShowEvent), evt *qt6.QShowEvent) {
 		go boot()
@@ -73,9 +82,17 @@ func boot() {
 	}
 	mainthread.Wait(func() {
 		renderGlyphs()
+		buildNavigator()
 		tableScroller.SetMaximum(int(blocks[len(blocks)-1].End) / 16)
 		tableScroller.SetValue(0)
 		onLink("0")
+
+		// Font files given on the command line, after Qt took its own flags
+		for _, path := range qt6.QCoreApplication_Arguments()[1:] {
+			if err := openFontFile(path); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
 	})
 }
 
@@ -0,0 +1,85 @@
+package gui
+
+import (
+	"fontview/sfnt"
+	"strings"
+
+	"github.com/mappu/miqt/qt6"
+)
+
+const (
+	style_FakeBold   = "Bold (synthesized)"
+	style_FakeItalic = "Italic (synthesized)"
+)
+
+var (
+	style_Family    string
+	style_ignoreEvt = false
+)
+
+// Fills styleBox with the faces of `family`, plus the styles Qt would have
+// to synthesize because no face provides them
+func updateStyles(family string) {
+	style_Family = family
+	style_ignoreEvt = true
+	defer func() { style_ignoreEvt = false }()
+
+	styleBox.Clear()
+	styles := qt6.QFontDatabase_Styles(family)
+	bold, italic, regular := false, false, 0
+	for idx, style := range styles {
+		isBold := qt6.QFontDatabase_Bold(family, style)
+		isItalic := qt6.QFontDatabase_Italic(family, style)
+		bold = bold || isBold
+		italic = italic || isItalic
+		if !isBold && !isItalic && qt6.QFontDatabase_Weight(family, style) == int(qt6.QFont__Normal) {
+			regular = idx
+		}
+	}
+	styleBox.AddItems(styles)
+	if !bold {
+		styleBox.AddItem(style_FakeBold)
+	}
+	if !italic {
+		styleBox.AddItem(style_FakeItalic)
+	}
+	styleBox.SetCurrentIndex(regular)
+}
+
+// The font for the face chosen in styleBox
+func styledFont(family string) *qt6.QFont {
+	if family != style_Family {
+		updateStyles(family)
+	}
+
+	font := qt6.NewQFont2(family)
+	switch style := styleBox.CurrentText(); style {
+	case style_FakeBold:
+		font.SetBold(true)
+	case style_FakeItalic:
+		font.SetItalic(true)
+	case "":
+	default:
+		font = qt6.QFontDatabase_Font(family, style, 12)
+	}
+	return font
+}
+
+// Styles Qt fakes for `font` because the face it picked, `face`, lacks them
+func fontSynthesis(font *qt6.QFont, face *sfnt.Font) []string {
+	ret := []string{}
+	os2, _ := face.OS2()
+	head, _ := face.Head()
+	if font.Weight() >= qt6.QFont__DemiBold && os2.WeightClass < 600 && head.MacStyle&0x1 == 0 {
+		ret = append(ret, "bold")
+	}
+	if font.Italic() && os2.FsSelection&0x201 == 0 && head.MacStyle&0x2 == 0 {
+		ret = append(ret, "italic")
+	}
+	return ret
+}
+
+// Cache key for a face, styles of a family can differ in coverage
+func fontKey(raw *qt6.QRawFont) string {
+	return strings.TrimSpace(raw.FamilyName() + " " + raw.StyleName())
+}
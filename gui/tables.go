@@ -2,10 +2,13 @@ package gui
 
 import (
 	"fmt"
+	"fontview/sfnt"
 	"fontview/tables"
+	"strings"
 	"sync"
 
 	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
 )
 
 var (
@@ -34,7 +37,7 @@ func renderGlyphs() {
 
 	for idx := rows / 3; idx <= rows/3*2; idx++ {
 		item := tableWidget.VerticalHeaderItem(idx)
-		text := fmt.Sprintf("%03X_", idx+sheetN)
+		text := rowHeader(idx + sheetN)
 		if item == nil {
 			item = qt6.NewQTableWidgetItem2(text)
 			item.SetFont(monoFont)
@@ -44,10 +47,15 @@ func renderGlyphs() {
 		}
 
 		for col := range 16 {
-			char := rune(16*(sheetN+idx) + col)
+			char, ok := cellRune(sheetN+idx, col)
+			if !ok {
+				clearGlyph(idx, col)
+				continue
+			}
 			renderGlyph(char, idx, col, curR, curC)
 		}
 	}
+	renderCompare()
 	tableMut.Unlock()
 }
 
@@ -69,25 +77,89 @@ func renderGlyph(char rune, idx, col, curR, curC int) {
 
 	label.SetText(render.Text)
 	s, t := label.Font(), render.Font
-	if s.Family() != t.Family() || s.PixelSize() != t.PixelSize() {
+	if s.Key() != t.Key() {
 		label.SetFont(render.Font)
 	}
-	if label.StyleSheet() != render.Style {
-		label.SetStyleSheet(render.Style)
+	if style := cmp_Style(char, render.Style, isCur); label.StyleSheet() != style {
+		label.SetStyleSheet(style)
+	}
+}
+
+func clearGlyph(idx, col int) {
+	cell := tableWidget.CellWidget(idx, col)
+	if cell == nil {
+		return
+	}
+
+	label := qt6.UnsafeNewQLabel(cell.Metacast("QLabel"))
+	label.SetText("")
+	label.SetStyleSheet("")
+}
+
+// Reads the tables of `raw` on the main thread, which owns it, so the face
+// parsed from them can be used from background goroutines
+func rawTables(raw *qt6.QRawFont) func(tag string) []byte {
+	return func(tag string) []byte {
+		main := qt6.QCoreApplication_Instance().Thread()
+		if qt6.QThread_CurrentThread().UnsafePointer() == main.UnsafePointer() {
+			return raw.FontTable(tag)
+		}
+		return mainthread.Wait2(func() []byte { return raw.FontTable(tag) })
 	}
 }
 
 func UpdateRealFont() {
 	w := tableWidget.ColumnWidth(0)
 	px := max(int(float64(w)*0.6), 4)
-	setFont := qt6.NewQFont2(fontBox.CurrentFont().Family())
-	setFont.SetPixelSize(px)
+	base := fontBox.CurrentFont().Family()
+	setFont := styledFont(base)
+	// Instances are built from the default face, so no style applies to them
+	instance := false
+	if family := variationFamily(base); family != base {
+		setFont = qt6.NewQFont2(family)
+		instance = true
+	}
+	bitmapFont(setFont, setFont.Family(), px)
 	rawFont := qt6.QRawFont_FromFont(setFont)
-	fontPair = FontPair{rawFont, setFont}
+	face := sfnt.New(rawTables(rawFont))
+	if file := fontFiles[fontKey(rawFont)]; file != nil {
+		face = file.Sfnt
+	}
+	fontPair = FontPair{rawFont, setFont, face, fontSynthesis(setFont, face)}
+	styleBox.SetEnabled(!instance)
+	styleBox.SetToolTip("Style")
+	if instance {
+		styleBox.SetToolTip("Style, unused while a variation instance is applied, its axes set weight and slant")
+	} else if len(fontPair.Synthetic) > 0 {
+		styleBox.SetToolTip(fmt.Sprintf(
+			"Style, %s is synthesized by Qt, the face has none", strings.Join(fontPair.Synthetic, " and "),
+		))
+	}
+	if tbl_compare {
+		updateCompare_Font()
+		updateCompare_Blocks()
+	}
 	renderGlyphs()
+	if curNode.Code != "" {
+		updateInfo_Preview(curNode)
+		updateInfo_Color(curNode)
+		updateInfo_Fallback(curNode)
+	}
+	updateNavigator()
+	updateInfo_Font()
+	updateFeatures_Font()
+	updateKerning_Font()
+	// The Supported column follows the font
+	if inspectTree != nil {
+		updateInspector()
+	}
+	if tbl_compact {
+		updateCompact()
+		return
+	}
 	go func() {
 		var maxRune uint
-		fam := fontPair.Raw.FamilyName()
+		fam := fontKey(fontPair.Raw)
 		maxGlyphMut.Lock()
 		done := maxGlyphSuccess[fam]
 		maxGlyphMut.Unlock()
@@ -128,6 +200,9 @@ func resizeGlyphs() {
 	labelCache = FontCache[Render]{}
 	tableWidget.SetRowCount((tableWidget.Size().Height() / tbl_col_w) * 3)
 	tableWidget.VerticalScrollBar().SetValue(tableWidget.RowCount() / 3)
+	if tbl_compare {
+		cmp_Resize()
+	}
 	UpdateRealFont()
 	renderGlyphs()
 }
@@ -146,6 +221,7 @@ func MakeTable() *qt6.QWidget {
 	bodyLayout.SetContentsMargins(0, 0, 0, 0)
 
 	bodyLayout.AddWidget(tableWidget.QWidget)
+	bodyLayout.AddWidget(makeCompare_Table())
 	bodyLayout.AddWidget(tableScroller.QWidget)
 
 	tableWidget.OnKeyPressEvent(tbl_KeyEvt)
@@ -156,6 +232,30 @@ func MakeTable() *qt6.QWidget {
 
 	tableWidget.SetSelectionBehavior(qt6.QAbstractItemView__SelectItems)
 	tableWidget.SetSelectionMode(qt6.QAbstractItemView__SingleSelection)
+	tableWidget.SetContextMenuPolicy(qt6.CustomContextMenu)
+	tableWidget.OnCustomContextMenuRequested(tbl_ContextMenu)
 
 	return bodyWidget
 }
+
+func tbl_ContextMenu(pos *qt6.QPoint) {
+	idx := tableWidget.IndexAt(pos)
+	if !idx.IsValid() {
+		return
+	}
+	sheetN := tableScroller.Value() - (tableWidget.RowCount() / 3)
+	char, ok := cellRune(sheetN+idx.Row(), idx.Column())
+	if !ok {
+		return
+	}
+
+	menu := qt6.NewQMenu(tableWidget.QWidget)
+	menu.AddActionWithText(fmt.Sprintf("Export U+%04X...", char)).OnTriggered(func() {
+		showExport(string(char))
+	})
+	menu.AddActionWithText("Outline Inspector...").OnTriggered(func() {
+		tableWidget.SetCurrentCell(idx.Row(), idx.Column())
+		showOutline()
+	})
+	menu.ExecWithPos(tableWidget.Viewport().MapToGlobalWithQPoint(pos))
+}
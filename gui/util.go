@@ -2,6 +2,7 @@ package gui
 
 import (
 	"fmt"
+	"fontview/tables"
 	"strconv"
 	"strings"
 
@@ -51,9 +52,30 @@ func onLink(link string) {
 	fmt.Println("Fwd: ", fwdStack)
 	btnBack.SetDisabled(len(backStack) == 0)
 	btnFwd.SetDisabled(len(fwdStack) == 0)
+	scrollToRune(rune(point))
+}
+
+func scrollToRune(point rune) {
 	third := tableWidget.RowCount() / 3
 	off := tableWidget.CurrentRow() - third
-	row := (point-point%16)/16 - off
-	tableScroller.SetValue(row)
-	tableWidget.SetCurrentCell(off+third, point%16)
+	row, col := runeCell(point)
+	tableScroller.SetValue(row - off)
+	tableWidget.SetCurrentCell(off+third, col)
+}
+
+func runeName(r rune) string {
+	node, ok := names[fmt.Sprintf("%04X", r)]
+	if !ok || node == nil {
+		return "<Undefined>"
+	}
+	return caser.String(node.Name)
+}
+
+func runeBlock(r rune) tables.Block {
+	for _, block := range blocks {
+		if block.Start <= r && r <= block.End {
+			return block
+		}
+	}
+	return tables.Block{Name: "Other", End: 0xFFFFFF}
 }
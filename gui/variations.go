@@ -0,0 +1,235 @@
+package gui
+
+import (
+	"fmt"
+	"fontview/sfnt"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+var (
+	varPanel     *qt6.QDockWidget
+	varLayout    *qt6.QVBoxLayout
+	varSummary   *qt6.QLabel
+	varInstances *qt6.QComboBox
+	varAxes      *qt6.QWidget
+	varSliders   []*qt6.QSlider
+	varSpins     []*qt6.QDoubleSpinBox
+	varTimer     *qt6.QTimer
+
+	var_Font      *sfnt.Font
+	var_Axes      []sfnt.Axis
+	var_Named     []sfnt.NamedInstance
+	var_Base      string
+	var_Family    string
+	var_AppFont   = -1
+	var_ignoreEvt = false
+	varGen        atomic.Int64
+)
+
+// Qt 6.4 can't set variation coordinates on a QFont, so the chosen
+// coordinates are baked into a static instance that gets registered as an
+// application font
+func MakeVariations() *qt6.QDockWidget {
+	widget := qt6.NewQWidget2()
+	varLayout = qt6.NewQVBoxLayout(widget)
+	varPanel = qt6.NewQDockWidget2("Variations")
+	varPanel.SetAllowedAreas(
+		qt6.BottomDockWidgetArea |
+			qt6.RightDockWidgetArea |
+			qt6.LeftDockWidgetArea,
+	)
+
+	varSummary = qt6.NewQLabel2()
+	varSummary.SetWordWrap(true)
+	varInstances = qt6.NewQComboBox2()
+	varInstances.SetToolTip("Named instances")
+	varInstances.OnCurrentIndexChanged(func(idx int) {
+		if var_ignoreEvt || idx <= 0 || idx > len(var_Named) {
+			return
+		}
+		variations_Set(var_Named[idx-1].Coords)
+	})
+	varAxes = qt6.NewQWidget2()
+
+	varLayout.AddWidget(varSummary.QWidget)
+	varLayout.AddWidget(varInstances.QWidget)
+	varLayout.AddWidget(varAxes)
+	varLayout.AddStretch()
+
+	varTimer = qt6.NewQTimer()
+	varTimer.OnTimerEvent(func(super func(evt *qt6.QTimerEvent), evt *qt6.QTimerEvent) {
+		varTimer.Stop()
+		applyVariations()
+	})
+
+	varPanel.SetWidget(widget)
+	return varPanel
+}
+
+// The family UpdateRealFont should use for `base`, the registered instance
+// if one has been chosen. Rebuilds the axes when the font changes.
+func variationFamily(base string) string {
+	if base == var_Base {
+		if var_Family != "" {
+			return var_Family
+		}
+		return base
+	}
+
+	var_Base = base
+	var_Family = ""
+	varGen.Add(1)
+	if var_AppFont >= 0 {
+		qt6.QFontDatabase_RemoveApplicationFont(var_AppFont)
+		var_AppFont = -1
+	}
+	raw := qt6.QRawFont_FromFont(qt6.NewQFont2(base))
+	var_Font = sfnt.New(rawTables(raw))
+	updateVariations()
+	return base
+}
+
+func updateVariations() {
+	var_Axes = var_Font.Axes()
+	var_Named = var_Font.NamedInstances()
+
+	var_ignoreEvt = true
+	defer func() { var_ignoreEvt = false }()
+
+	varLayout.RemoveWidget(varAxes)
+	varAxes.DeleteLater()
+	varAxes = qt6.NewQWidget2()
+	varLayout.InsertWidget(2, varAxes)
+	varSliders, varSpins = nil, nil
+
+	varInstances.Clear()
+	varInstances.SetVisible(len(var_Axes) > 0)
+	if len(var_Axes) == 0 {
+		varSummary.SetText(fmt.Sprintf("<b>%s</b> has no variation axes", var_Base))
+		return
+	}
+	varSummary.SetText(fmt.Sprintf(
+		"<b>%s</b>: %d axes, %d named instances", var_Base, len(var_Axes), len(var_Named),
+	))
+	if !var_Font.HasTable("glyf") {
+		varSummary.SetText(varSummary.Text() + "<br>CFF2 outlines can't be instanced, only the default is shown")
+	}
+
+	varInstances.AddItem("Default")
+	for _, inst := range var_Named {
+		varInstances.AddItem(inst.Name)
+	}
+
+	grid := qt6.NewQGridLayout(varAxes)
+	grid.SetContentsMargins(0, 0, 0, 0)
+	for idx, axis := range var_Axes {
+		label := qt6.NewQLabel3(axis.Name)
+		label.SetToolTip(fmt.Sprintf("%s, %g to %g, default %g", axis.Tag, axis.Min, axis.Max, axis.Default))
+		if axis.Hidden {
+			label.SetText(axis.Name + " (hidden)")
+		}
+
+		slider := qt6.NewQSlider3(qt6.Horizontal)
+		slider.SetRange(int(math.Round(axis.Min*100)), int(math.Round(axis.Max*100)))
+		slider.SetValue(int(math.Round(axis.Default * 100)))
+		spin := qt6.NewQDoubleSpinBox2()
+		spin.SetDecimals(2)
+		spin.SetRange(axis.Min, axis.Max)
+		spin.SetValue(axis.Default)
+
+		slider.OnValueChanged(func(value int) {
+			if var_ignoreEvt {
+				return
+			}
+			var_ignoreEvt = true
+			spin.SetValue(float64(value) / 100)
+			varInstances.SetCurrentIndex(0)
+			var_ignoreEvt = false
+			varTimer.Start(200)
+		})
+		spin.OnValueChanged(func(value float64) {
+			if var_ignoreEvt {
+				return
+			}
+			var_ignoreEvt = true
+			slider.SetValue(int(math.Round(value * 100)))
+			varInstances.SetCurrentIndex(0)
+			var_ignoreEvt = false
+			varTimer.Start(200)
+		})
+
+		grid.AddWidget2(label.QWidget, idx, 0)
+		grid.AddWidget2(slider.QWidget, idx, 1)
+		grid.AddWidget2(spin.QWidget, idx, 2)
+		varSliders = append(varSliders, slider)
+		varSpins = append(varSpins, spin)
+	}
+	grid.SetColumnStretch(1, 1)
+}
+
+// Moves the controls to `coords` and renders them
+func variations_Set(coords []float64) {
+	var_ignoreEvt = true
+	for idx, value := range coords {
+		if idx < len(varSliders) {
+			varSliders[idx].SetValue(int(math.Round(value * 100)))
+			varSpins[idx].SetValue(value)
+		}
+	}
+	var_ignoreEvt = false
+	varTimer.Start(0)
+}
+
+func applyVariations() {
+	gen := varGen.Add(1)
+	coords := make([]float64, len(varSpins))
+	labels := []string{}
+	isDefault := true
+	for idx, spin := range varSpins {
+		coords[idx] = spin.Value()
+		if coords[idx] != var_Axes[idx].Default {
+			isDefault = false
+		}
+		labels = append(labels, fmt.Sprintf("%s %g", var_Axes[idx].Tag, coords[idx]))
+	}
+
+	if isDefault {
+		var_Family = ""
+		UpdateRealFont()
+		return
+	}
+
+	font := var_Font
+	family := fmt.Sprintf("%s [%s]", var_Base, strings.Join(labels, ", "))
+	go func() {
+		data, err := font.Instance(coords, family)
+
+		mainthread.Wait(func() {
+			if varGen.Load() != gen {
+				return
+			}
+			if err != nil {
+				varSummary.SetText(fmt.Sprintf("<b>%s</b>: %s", var_Base, err))
+				return
+			}
+			id := qt6.QFontDatabase_AddApplicationFontFromData(data)
+			families := qt6.QFontDatabase_ApplicationFontFamilies(id)
+			if id < 0 || len(families) == 0 {
+				varSummary.SetText(fmt.Sprintf("<b>%s</b>: Qt rejected the instance", var_Base))
+				return
+			}
+
+			old := var_AppFont
+			var_AppFont, var_Family = id, families[0]
+			UpdateRealFont()
+			if old >= 0 {
+				qt6.QFontDatabase_RemoveApplicationFont(old)
+			}
+		})
+	}()
+}
@@ -0,0 +1,280 @@
+package sfnt
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Returns the entries of the CFF INDEX at `off` and the offset just past it
+func cffIndex(b []byte, off int) ([][]byte, int) {
+	count := int(u16(b, off))
+	if count == 0 {
+		return nil, off + 2
+	}
+
+	size := int(u8(b, off+2))
+	offsets := off + 3
+	data := offsets + (count+1)*size - 1
+	if size < 1 || size > 4 {
+		return nil, len(b)
+	}
+
+	read := func(idx int) int {
+		ret := 0
+		for n := range size {
+			ret = ret<<8 | int(u8(b, offsets+idx*size+n))
+		}
+		return data + ret
+	}
+
+	ret := make([][]byte, count)
+	start := read(0)
+	for idx := range count {
+		end := read(idx + 1)
+		if start < 0 || end < start || end > len(b) {
+			return ret[:idx], len(b)
+		}
+		ret[idx] = b[start:end]
+		start = end
+	}
+	return ret, start
+}
+
+// Operands of every operator in a CFF DICT, keyed by operator. Two byte
+// operators are stored as 1200 + second byte.
+func cffDict(b []byte) map[int][]float64 {
+	ret := map[int][]float64{}
+	operands := []float64{}
+
+	for off := 0; off < len(b); {
+		b0 := int(b[off])
+		switch {
+		case b0 == 12:
+			ret[1200+int(u8(b, off+1))] = operands
+			operands = []float64{}
+			off += 2
+		case b0 <= 21:
+			ret[b0] = operands
+			operands = []float64{}
+			off++
+		case b0 == 28:
+			operands = append(operands, float64(i16(b, off+1)))
+			off += 3
+		case b0 == 29:
+			operands = append(operands, float64(i32(b, off+1)))
+			off += 5
+		case b0 == 30:
+			num, size := cffReal(b[off+1:])
+			operands = append(operands, num)
+			off += 1 + size
+		case b0 >= 32 && b0 <= 246:
+			operands = append(operands, float64(b0-139))
+			off++
+		case b0 >= 247 && b0 <= 250:
+			operands = append(operands, float64((b0-247)*256+int(u8(b, off+1))+108))
+			off += 2
+		case b0 >= 251 && b0 <= 254:
+			operands = append(operands, float64(-(b0-251)*256-int(u8(b, off+1))-108))
+			off += 2
+		default:
+			off++
+		}
+	}
+	return ret
+}
+
+func cffReal(b []byte) (float64, int) {
+	str := ""
+	for idx, c := range b {
+		for _, nib := range []byte{c >> 4, c & 0xF} {
+			switch {
+			case nib <= 9:
+				str += string('0' + rune(nib))
+			case nib == 0xA:
+				str += "."
+			case nib == 0xB:
+				str += "E"
+			case nib == 0xC:
+				str += "E-"
+			case nib == 0xE:
+				str += "-"
+			case nib == 0xF:
+				num, _ := strconv.ParseFloat(str, 64)
+				return num, idx + 1
+			}
+		}
+	}
+	return 0, len(b)
+}
+
+func cffNames(cff []byte, num int) []string {
+	if len(cff) < 4 {
+		return nil
+	}
+
+	_, next := cffIndex(cff, int(u8(cff, 2)))
+	topDicts, next := cffIndex(cff, next)
+	strs, _ := cffIndex(cff, next)
+	if len(topDicts) == 0 {
+		return nil
+	}
+
+	top := cffDict(topDicts[0])
+	_, isCID := top[1230]
+	charset := 0
+	if ops := top[15]; len(ops) > 0 {
+		charset = int(ops[0])
+	}
+
+	sids := make([]int, num)
+	switch charset {
+	case 0:
+		// ISOAdobe, glyph IDs are SIDs
+		for gid := range sids {
+			sids[gid] = gid
+		}
+	case 1, 2:
+		// Expert charsets, too rare to bother with
+		return nil
+	default:
+		gid := 1
+		format := u8(cff, charset)
+		off := charset + 1
+		for gid < num && off < len(cff) {
+			switch format {
+			case 0:
+				sids[gid] = int(u16(cff, off))
+				gid++
+				off += 2
+			case 1, 2:
+				first := int(u16(cff, off))
+				left := int(u8(cff, off+2))
+				off += 3
+				if format == 2 {
+					left = int(u16(cff, off-1))
+					off++
+				}
+				// Range of `left` glyphs after the first, SIDs are sequential
+				for n := 0; n <= left && gid < num; n++ {
+					sids[gid] = first + n
+					gid++
+				}
+			default:
+				return nil
+			}
+		}
+	}
+
+	ret := make([]string, num)
+	for gid, sid := range sids {
+		switch {
+		case isCID:
+			ret[gid] = fmt.Sprintf("cid%05d", sid)
+		case sid < len(cffStrings):
+			ret[gid] = cffStrings[sid]
+		case sid-len(cffStrings) < len(strs):
+			ret[gid] = string(strs[sid-len(cffStrings)])
+		}
+	}
+	return ret
+}
+
+// The 391 standard strings every CFF font can refer to by SID
+var cffStrings = func() []string {
+	ret := []string{
+		".notdef", "space", "exclam", "quotedbl", "numbersign", "dollar",
+		"percent", "ampersand", "quoteright", "parenleft", "parenright",
+		"asterisk", "plus", "comma", "hyphen", "period", "slash",
+	}
+	ret = append(ret, digits("")...)
+	ret = append(ret, "colon", "semicolon", "less", "equal", "greater",
+		"question", "at")
+	ret = append(ret, letters('A', "")...)
+	ret = append(ret, "bracketleft", "backslash", "bracketright",
+		"asciicircum", "underscore", "quoteleft")
+	ret = append(ret, letters('a', "")...)
+	ret = append(ret,
+		"braceleft", "bar", "braceright", "asciitilde", "exclamdown", "cent",
+		"sterling", "fraction", "yen", "florin", "section", "currency",
+		"quotesingle", "quotedblleft", "guillemotleft", "guilsinglleft",
+		"guilsinglright", "fi", "fl", "endash", "dagger", "daggerdbl",
+		"periodcentered", "paragraph", "bullet", "quotesinglbase",
+		"quotedblbase", "quotedblright", "guillemotright", "ellipsis",
+		"perthousand", "questiondown", "grave", "acute", "circumflex", "tilde",
+		"macron", "breve", "dotaccent", "dieresis", "ring", "cedilla",
+		"hungarumlaut", "ogonek", "caron", "emdash", "AE", "ordfeminine",
+		"Lslash", "Oslash", "OE", "ordmasculine", "ae", "dotlessi", "lslash",
+		"oslash", "oe", "germandbls", "onesuperior", "logicalnot", "mu",
+		"trademark", "Eth", "onehalf", "plusminus", "Thorn", "onequarter",
+		"divide", "brokenbar", "degree", "thorn", "threequarters",
+		"twosuperior", "registered", "minus", "eth", "multiply",
+		"threesuperior", "copyright", "Aacute", "Acircumflex", "Adieresis",
+		"Agrave", "Aring", "Atilde", "Ccedilla", "Eacute", "Ecircumflex",
+		"Edieresis", "Egrave", "Iacute", "Icircumflex", "Idieresis", "Igrave",
+		"Ntilde", "Oacute", "Ocircumflex", "Odieresis", "Ograve", "Otilde",
+		"Scaron", "Uacute", "Ucircumflex", "Udieresis", "Ugrave", "Yacute",
+		"Ydieresis", "Zcaron", "aacute", "acircumflex", "adieresis", "agrave",
+		"aring", "atilde", "ccedilla", "eacute", "ecircumflex", "edieresis",
+		"egrave", "iacute", "icircumflex", "idieresis", "igrave", "ntilde",
+		"oacute", "ocircumflex", "odieresis", "ograve", "otilde", "scaron",
+		"uacute", "ucircumflex", "udieresis", "ugrave", "yacute", "ydieresis",
+		"zcaron", "exclamsmall", "Hungarumlautsmall", "dollaroldstyle",
+		"dollarsuperior", "ampersandsmall", "Acutesmall", "parenleftsuperior",
+		"parenrightsuperior", "twodotenleader", "onedotenleader",
+	)
+	ret = append(ret, digits("oldstyle")...)
+	ret = append(ret,
+		"commasuperior", "threequartersemdash", "periodsuperior",
+		"questionsmall", "asuperior", "bsuperior", "centsuperior", "dsuperior",
+		"esuperior", "isuperior", "lsuperior", "msuperior", "nsuperior",
+		"osuperior", "rsuperior", "ssuperior", "tsuperior", "ff", "ffi", "ffl",
+		"parenleftinferior", "parenrightinferior", "Circumflexsmall",
+		"hyphensuperior", "Gravesmall",
+	)
+	ret = append(ret, letters('A', "small")...)
+	ret = append(ret,
+		"colonmonetary", "onefitted", "rupiah", "Tildesmall",
+		"exclamdownsmall", "centoldstyle", "Lslashsmall", "Scaronsmall",
+		"Zcaronsmall", "Dieresissmall", "Brevesmall", "Caronsmall",
+		"Dotaccentsmall", "Macronsmall", "figuredash", "hypheninferior",
+		"Ogoneksmall", "Ringsmall", "Cedillasmall", "questiondownsmall",
+		"oneeighth", "threeeighths", "fiveeighths", "seveneighths", "onethird",
+		"twothirds", "zerosuperior", "foursuperior", "fivesuperior",
+		"sixsuperior", "sevensuperior", "eightsuperior", "ninesuperior",
+	)
+	ret = append(ret, digits("inferior")...)
+	ret = append(ret,
+		"centinferior", "dollarinferior", "periodinferior", "commainferior",
+		"Agravesmall", "Aacutesmall", "Acircumflexsmall", "Atildesmall",
+		"Adieresissmall", "Aringsmall", "AEsmall", "Ccedillasmall",
+		"Egravesmall", "Eacutesmall", "Ecircumflexsmall", "Edieresissmall",
+		"Igravesmall", "Iacutesmall", "Icircumflexsmall", "Idieresissmall",
+		"Ethsmall", "Ntildesmall", "Ogravesmall", "Oacutesmall",
+		"Ocircumflexsmall", "Otildesmall", "Odieresissmall", "OEsmall",
+		"Oslashsmall", "Ugravesmall", "Uacutesmall", "Ucircumflexsmall",
+		"Udieresissmall", "Yacutesmall", "Thornsmall", "Ydieresissmall",
+		"001.000", "001.001", "001.002", "001.003", "Black", "Bold", "Book",
+		"Light", "Medium", "Regular", "Roman", "Semibold",
+	)
+	return ret
+}()
+
+func digits(suffix string) []string {
+	names := []string{
+		"zero", "one", "two", "three", "four",
+		"five", "six", "seven", "eight", "nine",
+	}
+	ret := make([]string, len(names))
+	for idx, name := range names {
+		ret[idx] = name + suffix
+	}
+	return ret
+}
+
+func letters(first rune, suffix string) []string {
+	ret := make([]string, 26)
+	for idx := range ret {
+		ret[idx] = string(first+rune(idx)) + suffix
+	}
+	return ret
+}
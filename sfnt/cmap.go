@@ -0,0 +1,208 @@
+package sfnt
+
+import "slices"
+
+// Character to glyph ID mapping from the best Unicode subtable in the cmap
+func (f *Font) Cmap() (map[rune]uint16, error) {
+	f.mut.Lock()
+	cached := f.cmap
+	f.mut.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	b, symbol, err := f.cmapSubtable()
+	if err != nil {
+		return nil, err
+	}
+	ret, err := parseCmap(b, false)
+	if err != nil {
+		return nil, err
+	}
+	if symbol {
+		symbolRemap(ret)
+	}
+
+	f.mut.Lock()
+	f.cmap = ret
+	f.mut.Unlock()
+	return ret, nil
+}
+
+// Like Cmap, but keeps the code points the subtable explicitly maps to glyph
+// 0, .notdef, which Cmap treats as unmapped
+func (f *Font) RawCmap() (map[rune]uint16, error) {
+	b, symbol, err := f.cmapSubtable()
+	if err != nil {
+		return nil, err
+	}
+	ret, err := parseCmap(b, true)
+	if err != nil {
+		return nil, err
+	}
+	if symbol {
+		symbolRemap(ret)
+	}
+	return ret, nil
+}
+
+// The best Unicode subtable of the cmap, and whether it is the (3,0)
+// symbol subtable
+func (f *Font) cmapSubtable() ([]byte, bool, error) {
+	cmap := f.Table("cmap")
+	if len(cmap) < 4 {
+		return nil, false, ErrMissing
+	}
+
+	best, bestScore, symbol := -1, 0, false
+	numTables := int(u16(cmap, 2))
+	for idx := range numTables {
+		rec := 4 + idx*8
+		platform, encoding := u16(cmap, rec), u16(cmap, rec+2)
+		offset := int(u32(cmap, rec+4))
+		format := u16(cmap, offset)
+
+		score := 0
+		switch {
+		case (platform == 3 && encoding == 10) || (platform == 0 && encoding >= 4 && encoding != 5):
+			score = 5
+		case (platform == 3 && encoding == 1) || (platform == 0 && encoding < 4):
+			score = 4
+		case platform == 3 && encoding == 0:
+			score = 2
+		case platform == 1 && encoding == 0:
+			score = 1
+		}
+		if format == 14 {
+			// Variation sequences only, no base mapping
+			score = 0
+		}
+
+		if score > bestScore {
+			best, bestScore = offset, score
+			symbol = platform == 3 && encoding == 0
+		}
+	}
+
+	if best < 0 {
+		return nil, false, ErrFormat
+	}
+	return sub(cmap, best), symbol, nil
+}
+
+// Symbol fonts map their glyphs at U+F000-U+F0FF. Like Windows, Qt falls
+// back to those for Latin-1 code points the subtable leaves unmapped.
+func symbolRemap(cmap map[rune]uint16) {
+	for code := range rune(0x100) {
+		if _, ok := cmap[code]; ok {
+			continue
+		}
+		if gid, ok := cmap[0xF000+code]; ok {
+			cmap[code] = gid
+		}
+	}
+}
+
+// Glyph ID to every character mapped to it, in code point order
+func (f *Font) ReverseCmap() (map[uint16][]rune, error) {
+	cmap, err := f.Cmap()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[uint16][]rune{}
+	for r, gid := range cmap {
+		ret[gid] = append(ret[gid], r)
+	}
+	for _, runes := range ret {
+		slices.Sort(runes)
+	}
+	return ret, nil
+}
+
+// Code points mapped to glyph 0 are left out unless `notdef`
+func parseCmap(b []byte, notdef bool) (map[rune]uint16, error) {
+	ret := map[rune]uint16{}
+
+	switch u16(b, 0) {
+	case 0:
+		if len(b) < 6+256 {
+			return nil, ErrTruncated
+		}
+		for code := range 256 {
+			if gid := b[6+code]; gid != 0 || notdef {
+				ret[rune(code)] = uint16(gid)
+			}
+		}
+
+	case 4:
+		segX2 := int(u16(b, 6))
+		ends := 14
+		starts := ends + segX2 + 2
+		deltas := starts + segX2
+		ranges := deltas + segX2
+		if len(b) < ranges+segX2 {
+			return nil, ErrTruncated
+		}
+
+		for seg := 0; seg < segX2; seg += 2 {
+			start, end := u16(b, starts+seg), u16(b, ends+seg)
+			delta := u16(b, deltas+seg)
+			rangeOff := int(u16(b, ranges+seg))
+
+			for code := int(start); code <= int(end) && code != 0xFFFF; code++ {
+				var gid uint16
+				if rangeOff == 0 {
+					gid = uint16(code) + delta
+				} else {
+					addr := ranges + seg + rangeOff + 2*(code-int(start))
+					gid = u16(b, addr)
+					if gid != 0 {
+						gid += delta
+					}
+				}
+				if gid != 0 || notdef {
+					ret[rune(code)] = gid
+				}
+			}
+		}
+
+	case 6:
+		first, count := int(u16(b, 6)), int(u16(b, 8))
+		if len(b) < 10+2*count {
+			return nil, ErrTruncated
+		}
+		for idx := range count {
+			if gid := u16(b, 10+2*idx); gid != 0 || notdef {
+				ret[rune(first+idx)] = gid
+			}
+		}
+
+	case 12, 13:
+		groups := int(u32(b, 12))
+		if len(b) < 16+12*groups {
+			return nil, ErrTruncated
+		}
+		constant := u16(b, 0) == 13
+		for idx := range groups {
+			rec := 16 + 12*idx
+			start, end, gid := u32(b, rec), u32(b, rec+4), u32(b, rec+8)
+			if end > 0x10FFFF {
+				end = 0x10FFFF
+			}
+			for code := start; code <= end; code++ {
+				if gid != 0 || notdef {
+					ret[rune(code)] = uint16(gid)
+				}
+				if !constant {
+					gid++
+				}
+			}
+		}
+
+	default:
+		return nil, ErrFormat
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,280 @@
+package sfnt
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+)
+
+// Palette index that stands for the text colour
+const Foreground = 0xFFFF
+
+// A glyph drawn in a single colour, one step of a COLR glyph
+type ColorLayer struct {
+	Glyph   uint16
+	Palette uint16
+	Alpha   float64
+	// "solid", or the kind of gradient approximated by its first stop
+	Paint string
+}
+
+// The colour glyph technologies a glyph is covered by
+type GlyphColor struct {
+	// Version of the COLR entry, -1 when the glyph has none
+	COLR   int
+	Layers []ColorLayer
+	SVG    bool
+	// Bitmap strikes, eg "sbix 160ppem"
+	Bitmaps []string
+	Outline bool
+}
+
+type Palette struct {
+	Colors []color.NRGBA
+	// CPAL v1 flags, 0x1 for light backgrounds, 0x2 for dark ones
+	Type uint32
+	Name string
+}
+
+func u24(b []byte, off int) int {
+	return int(u8(b, off))<<16 | int(u16(b, off+1))
+}
+
+// Colour tables present, in the order they'd usually be preferred
+func (f *Font) ColorFormats() []string {
+	ret := []string{}
+	if colr := f.Table("COLR"); len(colr) > 0 {
+		ret = append(ret, fmt.Sprintf("COLRv%d", u16(colr, 0)))
+	}
+	for _, tag := range []string{"CPAL", "SVG ", "sbix", "CBDT", "EBDT"} {
+		if f.HasTable(tag) {
+			ret = append(ret, strings.TrimSpace(tag))
+		}
+	}
+	return ret
+}
+
+func (f *Font) Palettes() []Palette {
+	b := f.Table("CPAL")
+	entries, count := int(u16(b, 2)), int(u16(b, 4))
+	records := int(u32(b, 8))
+
+	var types, labels int
+	if u16(b, 0) >= 1 {
+		types = int(u32(b, 12+2*count))
+		labels = int(u32(b, 16+2*count))
+	}
+
+	ret := []Palette{}
+	for idx := range count {
+		first := int(u16(b, 12+2*idx))
+		palette := Palette{}
+		for entry := range entries {
+			rec := records + 4*(first+entry)
+			if rec+4 > len(b) {
+				break
+			}
+			// Stored as BGRA
+			palette.Colors = append(palette.Colors, color.NRGBA{b[rec+2], b[rec+1], b[rec], b[rec+3]})
+		}
+		if types > 0 {
+			palette.Type = u32(b, types+4*idx)
+		}
+		if labels > 0 {
+			if id := u16(b, labels+2*idx); id != 0xFFFF {
+				palette.Name = f.Name(id)
+			}
+		}
+		ret = append(ret, palette)
+	}
+	return ret
+}
+
+func (f *Font) GlyphColor(gid uint16) GlyphColor {
+	ret := GlyphColor{}
+	ret.COLR, ret.Layers = f.colrLayers(gid)
+	ret.SVG = f.svgCovers(gid)
+	ret.Bitmaps = append(f.sbixStrikes(gid), f.cblcStrikes(gid)...)
+	switch {
+	case f.HasTable("glyf"):
+		ret.Outline = len(f.glyphData(int(gid))) > 0
+	case f.HasTable("CFF "), f.HasTable("CFF2"):
+		ret.Outline = true
+	}
+	return ret
+}
+
+// Flattens the glyph's COLR entry into tinted layers. Version 1 paint graphs
+// are reduced to the glyphs they fill: transforms are dropped and gradients
+// take the colour of their first stop.
+func (f *Font) colrLayers(gid uint16) (int, []ColorLayer) {
+	b := f.Table("COLR")
+	if len(b) < 14 {
+		return -1, nil
+	}
+
+	// Paint graphs take precedence over the version 0 layer records
+	if paint := colrBasePaint(b, gid); paint > 0 {
+		ret := []ColorLayer{}
+		f.colrPaint(b, paint, 0, 0, &ret)
+		return 1, ret
+	}
+
+	records, layers := int(u32(b, 4)), int(u32(b, 8))
+	lo, hi := 0, int(u16(b, 2))
+	for lo < hi {
+		mid := (lo + hi) / 2
+		rec := records + 6*mid
+		switch glyph := u16(b, rec); {
+		case glyph == gid:
+			ret := []ColorLayer{}
+			first, num := int(u16(b, rec+2)), int(u16(b, rec+4))
+			for idx := range num {
+				layer := layers + 4*(first+idx)
+				ret = append(ret, ColorLayer{u16(b, layer), u16(b, layer+2), 1, "solid"})
+			}
+			return 0, ret
+		case glyph < gid:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return -1, nil
+}
+
+// Offset of the glyph's root paint in the version 1 BaseGlyphList, 0 if it
+// has none
+func colrBasePaint(b []byte, gid uint16) int {
+	list := int(u32(b, 14))
+	if u16(b, 0) < 1 || list == 0 {
+		return 0
+	}
+	lo, hi := 0, int(u32(b, list))
+	for lo < hi {
+		mid := (lo + hi) / 2
+		rec := list + 4 + 6*mid
+		switch glyph := u16(b, rec); {
+		case glyph == gid:
+			return list + int(u32(b, rec+2))
+		case glyph < gid:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0
+}
+
+var colrGradients = map[uint8]string{
+	4: "linear gradient", 5: "linear gradient",
+	6: "radial gradient", 7: "radial gradient",
+	8: "sweep gradient", 9: "sweep gradient",
+}
+
+// Walks the paint at `off`, `glyph` being the outline an enclosing PaintGlyph
+// clips to
+func (f *Font) colrPaint(b []byte, off int, glyph uint16, depth int, ret *[]ColorLayer) {
+	if off <= 0 || off >= len(b) || depth > 64 || len(*ret) > 1024 {
+		return
+	}
+	child := func(at int) int {
+		if rel := u24(b, off+at); rel > 0 {
+			return off + rel
+		}
+		return 0
+	}
+
+	switch format := u8(b, off); {
+	case format == 1:
+		// PaintColrLayers
+		list := int(u32(b, 18))
+		num, first := int(u8(b, off+1)), int(u32(b, off+2))
+		for idx := range num {
+			if list == 0 {
+				break
+			}
+			layer := list + 4 + 4*(first+idx)
+			f.colrPaint(b, list+int(u32(b, layer)), glyph, depth+1, ret)
+		}
+	case format == 2 || format == 3:
+		// PaintSolid
+		*ret = append(*ret, ColorLayer{glyph, u16(b, off+1), f2dot14(b, off+3), "solid"})
+	case colrGradients[format] != "":
+		line := child(1)
+		// First stop of the ColorLine, the same position for VarColorLine
+		*ret = append(*ret, ColorLayer{glyph, u16(b, line+5), f2dot14(b, line+7), colrGradients[format]})
+	case format == 10:
+		// PaintGlyph
+		f.colrPaint(b, child(1), u16(b, off+4), depth+1, ret)
+	case format == 11:
+		// PaintColrGlyph, within the same walk so cycles end at the depth limit
+		f.colrPaint(b, colrBasePaint(b, u16(b, off+1)), 0, depth+1, ret)
+	case format >= 12 && format <= 31:
+		// Transforms all keep their child paint first
+		f.colrPaint(b, child(1), glyph, depth+1, ret)
+	case format == 32:
+		// PaintComposite, backdrop below source
+		f.colrPaint(b, child(5), glyph, depth+1, ret)
+		f.colrPaint(b, child(1), glyph, depth+1, ret)
+	}
+}
+
+func (f *Font) svgCovers(gid uint16) bool {
+	b := f.Table("SVG ")
+	list := int(u32(b, 2))
+	if list == 0 {
+		return false
+	}
+	for idx := range int(u16(b, list)) {
+		rec := list + 2 + 12*idx
+		if gid >= u16(b, rec) && gid <= u16(b, rec+2) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Font) sbixStrikes(gid uint16) []string {
+	b := f.Table("sbix")
+	ret := []string{}
+	for idx := range int(u32(b, 4)) {
+		strike := int(u32(b, 8+4*idx))
+		glyph := strike + 4 + 4*int(gid)
+		if u32(b, glyph+4) > u32(b, glyph) {
+			ret = append(ret, fmt.Sprintf("sbix %dppem", u16(b, strike)))
+		}
+	}
+	return ret
+}
+
+// Strikes whose index subtables cover the glyph. Sparse subtable formats
+// can still leave it out, this only checks their ranges.
+func (f *Font) cblcStrikes(gid uint16) []string {
+	table := "CBDT"
+	b := f.Table("CBLC")
+	if len(b) == 0 {
+		table = "EBDT"
+		b = f.Table("EBLC")
+	}
+	ret := []string{}
+	for idx := range int(u32(b, 4)) {
+		size := 8 + 48*idx
+		if gid < u16(b, size+40) || gid > u16(b, size+42) {
+			continue
+		}
+		array := int(u32(b, size))
+		for sub := range int(u32(b, size+8)) {
+			rec := array + 8*sub
+			if gid >= u16(b, rec) && gid <= u16(b, rec+2) {
+				ppem := fmt.Sprintf("%s %dppem", table, u8(b, size+45))
+				if !slices.Contains(ret, ppem) {
+					ret = append(ret, ppem)
+				}
+				break
+			}
+		}
+	}
+	return ret
+}
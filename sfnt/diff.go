@@ -0,0 +1,138 @@
+package sfnt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A value that differs between two builds of a font
+type Change struct {
+	Table string
+	Field string
+	Old   string
+	New   string
+}
+
+// Code points the cmap of `after` maps and that of `before` doesn't, and the
+// other way around, in order
+func CmapChanges(before, after *Font) (added, removed []rune, err error) {
+	old, err := before.Cmap()
+	if err != nil {
+		return nil, nil, err
+	}
+	cur, err := after.Cmap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for r := range cur {
+		if _, ok := old[r]; !ok {
+			added = append(added, r)
+		}
+	}
+	for r := range old {
+		if _, ok := cur[r]; !ok {
+			removed = append(removed, r)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed, nil
+}
+
+// Differences in the head, hhea, OS/2 and maxp values, in table order. A
+// table only one of the fonts has shows up once, as missing.
+func MetricChanges(before, after *Font) []Change {
+	ret := []Change{}
+	add := func(table, field string, old, cur any) {
+		o, c := fmt.Sprint(old), fmt.Sprint(cur)
+		if o != c {
+			ret = append(ret, Change{table, field, o, c})
+		}
+	}
+	missing := func(table string, errOld, errCur error) bool {
+		if errOld != nil || errCur != nil {
+			add(table, "Table", errOld == nil, errCur == nil)
+			return true
+		}
+		return false
+	}
+
+	add("", "Format", before.Format(), after.Format())
+	oldHead, errOld := before.Head()
+	curHead, errCur := after.Head()
+	if !missing("head", errOld, errCur) {
+		add("head", "Revision", fmt.Sprintf("%.3f", oldHead.Revision), fmt.Sprintf("%.3f", curHead.Revision))
+		add("head", "Units per Em", oldHead.UnitsPerEm, curHead.UnitsPerEm)
+		add("head", "Flags", fmt.Sprintf("%016b", oldHead.Flags), fmt.Sprintf("%016b", curHead.Flags))
+		add("head", "Created", oldHead.Created, curHead.Created)
+		add("head", "Modified", oldHead.Modified, curHead.Modified)
+		add("head", "Bounding Box",
+			fmt.Sprintf("%d, %d to %d, %d", oldHead.XMin, oldHead.YMin, oldHead.XMax, oldHead.YMax),
+			fmt.Sprintf("%d, %d to %d, %d", curHead.XMin, curHead.YMin, curHead.XMax, curHead.YMax),
+		)
+		add("head", "Mac Style", oldHead.MacStyle, curHead.MacStyle)
+	}
+
+	oldHhea, errOld := before.Hhea()
+	curHhea, errCur := after.Hhea()
+	if !missing("hhea", errOld, errCur) {
+		add("hhea", "Ascender", oldHhea.Ascender, curHhea.Ascender)
+		add("hhea", "Descender", oldHhea.Descender, curHhea.Descender)
+		add("hhea", "Line Gap", oldHhea.LineGap, curHhea.LineGap)
+		add("hhea", "Max Advance", oldHhea.AdvanceWidthMax, curHhea.AdvanceWidthMax)
+		add("hhea", "Min Left Bearing", oldHhea.MinLeftBearing, curHhea.MinLeftBearing)
+		add("hhea", "Min Right Bearing", oldHhea.MinRightBearing, curHhea.MinRightBearing)
+		add("hhea", "Max Extent", oldHhea.XMaxExtent, curHhea.XMaxExtent)
+		add("hhea", "Metrics", oldHhea.NumberOfMetrics, curHhea.NumberOfMetrics)
+	}
+
+	oldOS2, errOld := before.OS2()
+	curOS2, errCur := after.OS2()
+	if !missing("OS/2", errOld, errCur) {
+		add("OS/2", "Version", oldOS2.Version, curOS2.Version)
+		add("OS/2", "Average Width", oldOS2.AvgCharWidth, curOS2.AvgCharWidth)
+		add("OS/2", "Weight", oldOS2.WeightClass, curOS2.WeightClass)
+		add("OS/2", "Width", oldOS2.WidthClass, curOS2.WidthClass)
+		add("OS/2", "Embedding", fmt.Sprintf("%#04x", oldOS2.FsType), fmt.Sprintf("%#04x", curOS2.FsType))
+		add("OS/2", "Selection", fmt.Sprintf("%016b", oldOS2.FsSelection), fmt.Sprintf("%016b", curOS2.FsSelection))
+		add("OS/2", "Vendor", oldOS2.Vendor, curOS2.Vendor)
+		add("OS/2", "PANOSE", oldOS2.Panose, curOS2.Panose)
+		add("OS/2", "Unicode Ranges", oldOS2.UnicodeRange, curOS2.UnicodeRange)
+		add("OS/2", "Code Pages", oldOS2.CodePageRange, curOS2.CodePageRange)
+		add("OS/2", "Typo Ascender", oldOS2.TypoAscender, curOS2.TypoAscender)
+		add("OS/2", "Typo Descender", oldOS2.TypoDescender, curOS2.TypoDescender)
+		add("OS/2", "Typo Line Gap", oldOS2.TypoLineGap, curOS2.TypoLineGap)
+		add("OS/2", "Win Ascent", oldOS2.WinAscent, curOS2.WinAscent)
+		add("OS/2", "Win Descent", oldOS2.WinDescent, curOS2.WinDescent)
+		add("OS/2", "x-Height", oldOS2.XHeight, curOS2.XHeight)
+		add("OS/2", "Cap Height", oldOS2.CapHeight, curOS2.CapHeight)
+	}
+
+	add("maxp", "Glyphs", before.NumGlyphs(), after.NumGlyphs())
+	return ret
+}
+
+// Differences in the name table by name ID, compared the way Name picks
+// the string for each
+func NameChanges(before, after *Font) []Change {
+	ids := []uint16{}
+	for _, rec := range slices.Concat(before.NameRecords(), after.NameRecords()) {
+		if !slices.Contains(ids, rec.ID) {
+			ids = append(ids, rec.ID)
+		}
+	}
+	slices.Sort(ids)
+
+	ret := []Change{}
+	for _, id := range ids {
+		if old, cur := before.Name(id), after.Name(id); old != cur {
+			field, ok := NameIDs[id]
+			if !ok {
+				field = fmt.Sprintf("Name %d", id)
+			}
+			ret = append(ret, Change{"name", field, old, cur})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,154 @@
+package sfnt
+
+import "fmt"
+
+// Human readable name of a registered OpenType feature tag
+func FeatureName(tag string) string {
+	if name, ok := featureNames[tag]; ok {
+		return name
+	}
+
+	var n int
+	if _, err := fmt.Sscanf(tag, "ss%02d", &n); err == nil && n >= 1 && n <= 20 {
+		return fmt.Sprintf("Stylistic Set %d", n)
+	}
+	if _, err := fmt.Sscanf(tag, "cv%02d", &n); err == nil && n >= 1 {
+		return fmt.Sprintf("Character Variant %d", n)
+	}
+	return ""
+}
+
+// Features shapers apply unless told otherwise
+var DefaultFeatures = map[string]bool{
+	"abvf": true, "abvm": true, "abvs": true, "akhn": true, "blwf": true,
+	"blwm": true, "blws": true, "calt": true, "ccmp": true, "cfar": true,
+	"cjct": true, "clig": true, "curs": true, "dist": true, "fina": true,
+	"half": true, "haln": true, "init": true, "isol": true, "kern": true,
+	"liga": true, "ljmo": true, "locl": true, "mark": true, "med2": true,
+	"medi": true, "mkmk": true, "nukt": true, "pref": true, "pres": true,
+	"pstf": true, "psts": true, "rclt": true, "rkrf": true, "rlig": true,
+	"rphf": true, "rvrn": true, "tjmo": true, "vjmo": true,
+}
+
+// Default features a shaper only applies to some of the glyphs, by joining
+// position, Hangul syllable position or language. Layout.Apply can't tell
+// which, so would apply them to every glyph they match.
+var MaskedFeatures = map[string]bool{
+	"fin2": true, "fin3": true, "fina": true, "init": true, "isol": true,
+	"ljmo": true, "locl": true, "med2": true, "medi": true, "tjmo": true,
+	"vjmo": true,
+}
+
+var featureNames = map[string]string{
+	"aalt": "Access All Alternates",
+	"abvf": "Above-base Forms",
+	"abvm": "Above-base Mark Positioning",
+	"abvs": "Above-base Substitutions",
+	"afrc": "Alternative Fractions",
+	"akhn": "Akhand",
+	"blwf": "Below-base Forms",
+	"blwm": "Below-base Mark Positioning",
+	"blws": "Below-base Substitutions",
+	"c2pc": "Petite Capitals From Capitals",
+	"c2sc": "Small Capitals From Capitals",
+	"calt": "Contextual Alternates",
+	"case": "Case-Sensitive Forms",
+	"ccmp": "Glyph Composition / Decomposition",
+	"cfar": "Conjunct Form After Ro",
+	"cjct": "Conjunct Forms",
+	"clig": "Contextual Ligatures",
+	"cpct": "Centered CJK Punctuation",
+	"cpsp": "Capital Spacing",
+	"cswh": "Contextual Swash",
+	"curs": "Cursive Positioning",
+	"dist": "Distances",
+	"dlig": "Discretionary Ligatures",
+	"dnom": "Denominators",
+	"expt": "Expert Forms",
+	"falt": "Final Glyph on Line Alternates",
+	"fina": "Terminal Forms",
+	"frac": "Fractions",
+	"fwid": "Full Widths",
+	"half": "Half Forms",
+	"haln": "Halant Forms",
+	"halt": "Alternate Half Widths",
+	"hist": "Historical Forms",
+	"hkna": "Horizontal Kana Alternates",
+	"hlig": "Historical Ligatures",
+	"hwid": "Half Widths",
+	"init": "Initial Forms",
+	"isol": "Isolated Forms",
+	"ital": "Italics",
+	"jalt": "Justification Alternates",
+	"jp78": "JIS78 Forms",
+	"jp83": "JIS83 Forms",
+	"jp90": "JIS90 Forms",
+	"jp04": "JIS2004 Forms",
+	"kern": "Kerning",
+	"lfbd": "Left Bounds",
+	"liga": "Standard Ligatures",
+	"ljmo": "Leading Jamo Forms",
+	"lnum": "Lining Figures",
+	"locl": "Localized Forms",
+	"mark": "Mark Positioning",
+	"med2": "Medial Forms #2",
+	"medi": "Medial Forms",
+	"mgrk": "Mathematical Greek",
+	"mkmk": "Mark to Mark Positioning",
+	"nalt": "Alternate Annotation Forms",
+	"nlck": "NLC Kanji Forms",
+	"nukt": "Nukta Forms",
+	"numr": "Numerators",
+	"onum": "Oldstyle Figures",
+	"opbd": "Optical Bounds",
+	"ordn": "Ordinals",
+	"ornm": "Ornaments",
+	"palt": "Proportional Alternate Widths",
+	"pcap": "Petite Capitals",
+	"pkna": "Proportional Kana",
+	"pnum": "Proportional Figures",
+	"pref": "Pre-base Forms",
+	"pres": "Pre-base Substitutions",
+	"pstf": "Post-base Forms",
+	"psts": "Post-base Substitutions",
+	"pwid": "Proportional Widths",
+	"qwid": "Quarter Widths",
+	"rand": "Randomize",
+	"rclt": "Required Contextual Alternates",
+	"rkrf": "Rakar Forms",
+	"rlig": "Required Ligatures",
+	"rphf": "Reph Form",
+	"rtbd": "Right Bounds",
+	"rtla": "Right-to-left Alternates",
+	"rtlm": "Right-to-left Mirrored Forms",
+	"ruby": "Ruby Notation Forms",
+	"rvrn": "Required Variation Alternates",
+	"salt": "Stylistic Alternates",
+	"sinf": "Scientific Inferiors",
+	"size": "Optical Size",
+	"smcp": "Small Capitals",
+	"smpl": "Simplified Forms",
+	"ssty": "Math Script Style Alternates",
+	"stch": "Stretching Glyph Decomposition",
+	"subs": "Subscript",
+	"sups": "Superscript",
+	"swsh": "Swash",
+	"titl": "Titling",
+	"tjmo": "Trailing Jamo Forms",
+	"tnam": "Traditional Name Forms",
+	"tnum": "Tabular Figures",
+	"trad": "Traditional Forms",
+	"twid": "Third Widths",
+	"unic": "Unicase",
+	"valt": "Alternate Vertical Metrics",
+	"vatu": "Vattu Variants",
+	"vert": "Vertical Writing",
+	"vhal": "Alternate Vertical Half Metrics",
+	"vjmo": "Vowel Jamo Forms",
+	"vkna": "Vertical Kana Alternates",
+	"vkrn": "Vertical Kerning",
+	"vpal": "Proportional Alternate Vertical Metrics",
+	"vrt2": "Vertical Alternates and Rotation",
+	"vrtr": "Vertical Alternates for Rotation",
+	"zero": "Slashed Zero",
+}
@@ -0,0 +1,102 @@
+package sfnt
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Number of faces in a font file, 1 for a plain sfnt and 0 if the data
+// isn't one or its collection header is cut short
+func NumFaces(data []byte) int {
+	switch tag(data, 0) {
+	case "ttcf":
+		// Bounded by the offsets present before anything loops over it
+		num := int(u32(data, 8))
+		if 12+4*num > len(data) {
+			return 0
+		}
+		return num
+	case "\x00\x01\x00\x00", "OTTO", "true":
+		return 1
+	}
+	return 0
+}
+
+// Reads the first face of a font file
+func Parse(data []byte) (*Font, error) {
+	return ParseFace(data, 0)
+}
+
+// Reads face `idx` of a collection. The tables stay slices of `data`, so it
+// must not be modified afterwards.
+func ParseFace(data []byte, idx int) (*Font, error) {
+	num := NumFaces(data)
+	if num == 0 {
+		return nil, ErrFormat
+	}
+	if idx < 0 || idx >= num {
+		return nil, fmt.Errorf("sfnt: face %d of %d", idx, num)
+	}
+
+	dir := 0
+	if num > 1 || tag(data, 0) == "ttcf" {
+		dir = int(u32(data, 12+4*idx))
+	}
+	count := int(u16(data, dir+4))
+	if dir+12+16*count > len(data) {
+		return nil, ErrTruncated
+	}
+
+	tables := map[string][]byte{}
+	for n := range count {
+		rec := dir + 12 + 16*n
+		off, length := int(u32(data, rec+8)), int(u32(data, rec+12))
+		if off+length > len(data) {
+			return nil, ErrTruncated
+		}
+		tables[tag(data, rec)] = data[off : off+length]
+	}
+
+	f := New(func(tag string) []byte {
+		return tables[tag]
+	})
+	f.tags = slices.Sorted(maps.Keys(tables))
+	return f, nil
+}
+
+// Tags of the tables in the file, only known for fonts read with Parse
+func (f *Font) Tags() []string {
+	return f.tags
+}
+
+func (f *Font) fileTables() map[string][]byte {
+	ret := map[string][]byte{}
+	for _, tag := range f.tags {
+		ret[tag] = f.Table(tag)
+	}
+	return ret
+}
+
+// The face as a font file of its own, to pull a face out of a collection
+func (f *Font) Standalone() ([]byte, error) {
+	if len(f.tags) == 0 {
+		return nil, ErrMissing
+	}
+	return Assemble(f.fileTables()), nil
+}
+
+// Like Standalone, with the family renamed so it can be registered next to
+// an installed copy of the same font
+func (f *Font) Renamed(family string) ([]byte, error) {
+	if len(f.tags) == 0 {
+		return nil, ErrMissing
+	}
+	style := f.Name(17)
+	if style == "" {
+		style = f.Name(2)
+	}
+	tables := f.fileTables()
+	tables["name"] = f.renamedNames(family, style)
+	return Assemble(tables), nil
+}
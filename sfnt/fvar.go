@@ -0,0 +1,113 @@
+package sfnt
+
+// A design axis of a variable font, in user space units
+type Axis struct {
+	Tag     string
+	Min     float64
+	Default float64
+	Max     float64
+	// Not meant to be shown in user interfaces
+	Hidden bool
+	Name   string
+}
+
+// A named instance of the fvar table, eg "Bold Condensed"
+type NamedInstance struct {
+	Name   string
+	Coords []float64
+}
+
+func (f *Font) Axes() []Axis {
+	b := f.Table("fvar")
+	off, count, size := int(u16(b, 4)), int(u16(b, 8)), int(u16(b, 10))
+
+	ret := []Axis{}
+	for idx := range count {
+		rec := off + size*idx
+		if rec+20 > len(b) {
+			break
+		}
+		name := f.Name(u16(b, rec+18))
+		if name == "" {
+			name = tag(b, rec)
+		}
+		ret = append(ret, Axis{
+			Tag:     tag(b, rec),
+			Min:     fixed(b, rec+4),
+			Default: fixed(b, rec+8),
+			Max:     fixed(b, rec+12),
+			Hidden:  u16(b, rec+16)&0x1 != 0,
+			Name:    name,
+		})
+	}
+	return ret
+}
+
+func (f *Font) NamedInstances() []NamedInstance {
+	b := f.Table("fvar")
+	axesOff, axisCount, axisSize := int(u16(b, 4)), int(u16(b, 8)), int(u16(b, 10))
+	count, size := int(u16(b, 12)), int(u16(b, 14))
+
+	ret := []NamedInstance{}
+	for idx := range count {
+		rec := axesOff + axisCount*axisSize + size*idx
+		if rec+4+4*axisCount > len(b) {
+			break
+		}
+		inst := NamedInstance{
+			Name:   f.Name(u16(b, rec)),
+			Coords: make([]float64, axisCount),
+		}
+		for axis := range axisCount {
+			inst.Coords[axis] = fixed(b, rec+4+4*axis)
+		}
+		ret = append(ret, inst)
+	}
+	return ret
+}
+
+func f2dot14(b []byte, off int) float64 {
+	return float64(i16(b, off)) / 16384
+}
+
+// Maps user space coordinates to the -1..1 range gvar works in, including
+// the avar segment maps
+func (f *Font) normalize(axes []Axis, coords []float64) []float64 {
+	ret := make([]float64, len(axes))
+	for idx, axis := range axes {
+		value := axis.Default
+		if idx < len(coords) {
+			value = min(max(coords[idx], axis.Min), axis.Max)
+		}
+		switch {
+		case value < axis.Default && axis.Default > axis.Min:
+			ret[idx] = (value - axis.Default) / (axis.Default - axis.Min)
+		case value > axis.Default && axis.Max > axis.Default:
+			ret[idx] = (value - axis.Default) / (axis.Max - axis.Default)
+		}
+	}
+
+	avar := f.Table("avar")
+	off := 8
+	for idx := range min(int(u16(avar, 6)), len(ret)) {
+		count := int(u16(avar, off))
+		maps := off + 2
+		off = maps + 4*count
+
+		value := ret[idx]
+		for n := 1; n < count; n++ {
+			from0, to0 := f2dot14(avar, maps+4*(n-1)), f2dot14(avar, maps+4*(n-1)+2)
+			from1, to1 := f2dot14(avar, maps+4*n), f2dot14(avar, maps+4*n+2)
+			if value < from0 || value > from1 {
+				continue
+			}
+			if from1 == from0 {
+				ret[idx] = to0
+			} else {
+				ret[idx] = to0 + (to1-to0)*(value-from0)/(from1-from0)
+			}
+			break
+		}
+	}
+	return ret
+}
@@ -0,0 +1,327 @@
+package sfnt
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// A point of a TrueType outline, in font units with y going up
+type OutlinePoint struct {
+	X, Y    float64
+	OnCurve bool
+}
+
+type point struct {
+	x, y float64
+}
+
+type glyfComponent struct {
+	flags     uint16
+	gid       uint16
+	dx, dy    int
+	transform []byte
+}
+
+// A glyph of the glyf table, either an outline or a list of components
+type glyfGlyph struct {
+	xMin, yMin, xMax, yMax int16
+
+	contours     []int
+	points       []point
+	flags        []byte
+	components   []glyfComponent
+	instructions []byte
+}
+
+func parseGlyph(b []byte) glyfGlyph {
+	g := glyfGlyph{}
+	if len(b) < 10 {
+		return g
+	}
+	g.xMin, g.yMin, g.xMax, g.yMax = i16(b, 2), i16(b, 4), i16(b, 6), i16(b, 8)
+
+	contours := int(i16(b, 0))
+	if contours < 0 {
+		off, instructions := 10, false
+		for off+4 <= len(b) {
+			c := glyfComponent{flags: u16(b, off), gid: u16(b, off+2)}
+			off += 4
+			switch {
+			case c.flags&0x1 != 0:
+				c.dx, c.dy = int(i16(b, off)), int(i16(b, off+2))
+				off += 4
+			case c.flags&0x2 != 0:
+				c.dx, c.dy = int(int8(u8(b, off))), int(int8(u8(b, off+1)))
+				off += 2
+			default:
+				c.dx, c.dy = int(u8(b, off)), int(u8(b, off+1))
+				off += 2
+			}
+
+			size := 0
+			switch {
+			case c.flags&0x8 != 0:
+				size = 2
+			case c.flags&0x40 != 0:
+				size = 4
+			case c.flags&0x80 != 0:
+				size = 8
+			}
+			c.transform = sub(b, off)[:min(size, len(sub(b, off)))]
+			off += size
+
+			instructions = instructions || c.flags&0x100 != 0
+			g.components = append(g.components, c)
+			if c.flags&0x20 == 0 {
+				break
+			}
+		}
+		if instructions {
+			length := int(u16(b, off))
+			g.instructions = sub(b, off+2)[:min(length, len(sub(b, off+2)))]
+		}
+		return g
+	}
+
+	off := 10
+	for idx := range contours {
+		end := int(u16(b, off))
+		// Every contour ends past the last one, anything else can't be drawn
+		if idx > 0 && end <= g.contours[idx-1] {
+			return glyfGlyph{}
+		}
+		g.contours = append(g.contours, end)
+		off += 2
+	}
+	count := 0
+	if contours > 0 {
+		count = g.contours[contours-1] + 1
+	}
+	length := int(u16(b, off))
+	g.instructions = sub(b, off+2)[:min(length, len(sub(b, off+2)))]
+	off += 2 + length
+
+	g.flags = make([]byte, count)
+	for idx := 0; idx < count && off < len(b); {
+		flag := u8(b, off)
+		off++
+		repeat := 0
+		if flag&0x8 != 0 {
+			repeat = int(u8(b, off))
+			off++
+		}
+		for range repeat + 1 {
+			if idx < count {
+				g.flags[idx] = flag
+				idx++
+			}
+		}
+	}
+
+	g.points = make([]point, count)
+	x, y := 0, 0
+	for idx, flag := range g.flags {
+		switch {
+		case flag&0x02 != 0:
+			delta := int(u8(b, off))
+			if flag&0x10 == 0 {
+				delta = -delta
+			}
+			x += delta
+			off++
+		case flag&0x10 == 0:
+			x += int(i16(b, off))
+			off += 2
+		}
+		g.points[idx].x = float64(x)
+	}
+	for idx, flag := range g.flags {
+		switch {
+		case flag&0x04 != 0:
+			delta := int(u8(b, off))
+			if flag&0x20 == 0 {
+				delta = -delta
+			}
+			y += delta
+			off++
+		case flag&0x20 == 0:
+			y += int(i16(b, off))
+			off += 2
+		}
+		g.points[idx].y = float64(y)
+	}
+	return g
+}
+
+// Number of points gvar has deltas for, not counting the phantom points
+func (g *glyfGlyph) numPoints() int {
+	if g.components != nil {
+		return len(g.components)
+	}
+	return len(g.points)
+}
+
+// Serializes the glyph again, with all coordinates as words for simplicity.
+// The bounding box of composites is kept as is.
+func (g *glyfGlyph) encode() []byte {
+	be := binary.BigEndian
+	var b []byte
+
+	if g.components != nil {
+		b = be.AppendUint16(b, 0xFFFF)
+		b = be.AppendUint16(b, uint16(g.xMin))
+		b = be.AppendUint16(b, uint16(g.yMin))
+		b = be.AppendUint16(b, uint16(g.xMax))
+		b = be.AppendUint16(b, uint16(g.yMax))
+		for idx, c := range g.components {
+			last := idx == len(g.components)-1
+			flags := (c.flags | 0x1) &^ (0x20 | 0x100)
+			if !last {
+				flags |= 0x20
+			}
+			if last && len(g.instructions) > 0 {
+				flags |= 0x100
+			}
+			b = be.AppendUint16(b, flags)
+			b = be.AppendUint16(b, c.gid)
+			b = be.AppendUint16(b, uint16(c.dx))
+			b = be.AppendUint16(b, uint16(c.dy))
+			b = append(b, c.transform...)
+		}
+		if len(g.instructions) > 0 {
+			b = be.AppendUint16(b, uint16(len(g.instructions)))
+			b = append(b, g.instructions...)
+		}
+	} else if len(g.contours) > 0 {
+		xMin, yMin := math.Inf(1), math.Inf(1)
+		xMax, yMax := math.Inf(-1), math.Inf(-1)
+		for _, pt := range g.points {
+			xMin, yMin = min(xMin, pt.x), min(yMin, pt.y)
+			xMax, yMax = max(xMax, pt.x), max(yMax, pt.y)
+		}
+		b = be.AppendUint16(b, uint16(len(g.contours)))
+		b = be.AppendUint16(b, uint16(int16(xMin)))
+		b = be.AppendUint16(b, uint16(int16(yMin)))
+		b = be.AppendUint16(b, uint16(int16(xMax)))
+		b = be.AppendUint16(b, uint16(int16(yMax)))
+		for _, end := range g.contours {
+			b = be.AppendUint16(b, uint16(end))
+		}
+		b = be.AppendUint16(b, uint16(len(g.instructions)))
+		b = append(b, g.instructions...)
+		for _, flag := range g.flags {
+			// On curve and overlap bits, coordinates follow as words
+			b = append(b, flag&0x41)
+		}
+		x, y := 0, 0
+		for _, pt := range g.points {
+			b = be.AppendUint16(b, uint16(int16(int(pt.x)-x)))
+			x = int(pt.x)
+		}
+		for _, pt := range g.points {
+			b = be.AppendUint16(b, uint16(int16(int(pt.y)-y)))
+			y = int(pt.y)
+		}
+	}
+
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// Raw glyf entry of a glyph, empty if it has no outline
+func (f *Font) glyphData(gid int) []byte {
+	glyf, loca := f.Table("glyf"), f.Table("loca")
+	lo, hi := 0, 0
+	if i16(f.Table("head"), 50) == 1 {
+		lo, hi = int(u32(loca, 4*gid)), int(u32(loca, 4*gid+4))
+	} else {
+		lo, hi = 2*int(u16(loca, 2*gid)), 2*int(u16(loca, 2*gid+2))
+	}
+	if lo >= hi || hi > len(glyf) {
+		return nil
+	}
+	return glyf[lo:hi]
+}
+
+// The points of a TrueType glyph as stored, along with the index of the last
+// point of every contour. Composites are resolved into the points of their
+// components, moved and transformed into place. Empty for CFF outlines.
+func (f *Font) GlyphPoints(gid uint16) ([]OutlinePoint, []int) {
+	// Bounds the glyphs read, components may fan out at every level
+	budget := 1024
+	return f.glyphPoints(gid, 0, &budget)
+}
+
+func (f *Font) glyphPoints(gid uint16, depth int, budget *int) ([]OutlinePoint, []int) {
+	if *budget <= 0 {
+		return nil, nil
+	}
+	*budget--
+	g := parseGlyph(f.glyphData(int(gid)))
+	if g.components == nil {
+		ret := make([]OutlinePoint, len(g.points))
+		for idx, pt := range g.points {
+			ret[idx] = OutlinePoint{pt.x, pt.y, g.flags[idx]&0x1 != 0}
+		}
+		return ret, g.contours
+	}
+	if depth > 8 {
+		return nil, nil
+	}
+
+	ret, ends := []OutlinePoint{}, []int{}
+	for _, c := range g.components {
+		points, contours := f.glyphPoints(c.gid, depth+1, budget)
+
+		// x' = a*x + c*y, y' = b*x + d*y
+		a, b, cc, d := 1.0, 0.0, 0.0, 1.0
+		switch len(c.transform) {
+		case 2:
+			a = f2dot14(c.transform, 0)
+			d = a
+		case 4:
+			a, d = f2dot14(c.transform, 0), f2dot14(c.transform, 2)
+		case 8:
+			a, b = f2dot14(c.transform, 0), f2dot14(c.transform, 2)
+			cc, d = f2dot14(c.transform, 4), f2dot14(c.transform, 6)
+		}
+		for idx, pt := range points {
+			points[idx].X, points[idx].Y = a*pt.X+cc*pt.Y, b*pt.X+d*pt.Y
+		}
+
+		dx, dy := 0.0, 0.0
+		if c.flags&0x2 != 0 {
+			dx, dy = float64(c.dx), float64(c.dy)
+			if c.flags&0x800 != 0 {
+				dx, dy = a*dx+cc*dy, b*dx+d*dy
+			}
+		} else {
+			// Point numbers, the component point lands on the parent point
+			parent, child := int(uint16(c.dx)), int(uint16(c.dy))
+			if parent < len(ret) && child < len(points) {
+				dx, dy = ret[parent].X-points[child].X, ret[parent].Y-points[child].Y
+			}
+		}
+
+		base := len(ret)
+		for _, pt := range points {
+			ret = append(ret, OutlinePoint{pt.X + dx, pt.Y + dy, pt.OnCurve})
+		}
+		for _, end := range contours {
+			ends = append(ends, base+end)
+		}
+	}
+	return ret, ends
+}
+
+// Advance width and left side bearing from hmtx, in font units
+func (f *Font) HMetrics(gid uint16) (advance, lsb int) {
+	hmtx := f.Table("hmtx")
+	metrics := max(int(u16(f.Table("hhea"), 34)), 1)
+	if int(gid) < metrics {
+		return int(u16(hmtx, 4*int(gid))), int(i16(hmtx, 4*int(gid)+2))
+	}
+	return int(u16(hmtx, 4*(metrics-1))), int(i16(hmtx, 4*metrics+2*(int(gid)-metrics)))
+}
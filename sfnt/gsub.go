@@ -0,0 +1,245 @@
+package sfnt
+
+import (
+	"slices"
+)
+
+const (
+	SubstSingle    = 1
+	SubstMultiple  = 2
+	SubstAlternate = 3
+	SubstLigature  = 4
+	SubstContext   = 5
+	SubstChained   = 6
+	SubstReverse   = 8
+)
+
+// One substitution a GSUB lookup can make. Alternate substitutions list
+// every choice in Output.
+type Substitution struct {
+	Lookup int
+	Type   int
+	Input  []uint16
+	Output []uint16
+	// Only applies in some surroundings, as a nested lookup of a contextual
+	// or reverse chaining lookup
+	Contextual bool
+}
+
+// Substitutions the lookup makes with `gid` as the first input glyph
+func (l *Layout) Substitutions(lookup int, gid uint16) []Substitution {
+	return l.substitutions(lookup, gid, map[int]bool{})
+}
+
+func (l *Layout) substitutions(lookup int, gid uint16, seen map[int]bool) []Substitution {
+	if lookup < 0 || lookup >= len(l.Lookups) || seen[lookup] {
+		return nil
+	}
+	seen[lookup] = true
+
+	ret := []Substitution{}
+	kind := l.Lookups[lookup].Type
+	add := func(input, output []uint16) {
+		ret = append(ret, Substitution{
+			Lookup: lookup,
+			Type:   kind,
+			Input:  input,
+			Output: output,
+		})
+	}
+
+	for _, st := range l.Lookups[lookup].Subtables {
+		switch kind {
+		case SubstContext, SubstChained:
+			for _, nested := range contextLookups(st, kind == SubstChained) {
+				for _, subst := range l.substitutions(nested, gid, seen) {
+					subst.Contextual = true
+					ret = append(ret, subst)
+				}
+			}
+			continue
+		}
+
+		idx := coverageIndex(sub(st, int(u16(st, 2))), gid)
+		if idx < 0 {
+			continue
+		}
+
+		switch kind {
+		case SubstSingle:
+			add([]uint16{gid}, []uint16{singleSubst(st, gid, idx)})
+
+		case SubstMultiple, SubstAlternate:
+			seq := sub(st, int(u16(st, 6+2*idx)))
+			out := []uint16{}
+			for n := range int(u16(seq, 0)) {
+				out = append(out, u16(seq, 2+2*n))
+			}
+			add([]uint16{gid}, out)
+
+		case SubstLigature:
+			set := sub(st, int(u16(st, 6+2*idx)))
+			for n := range int(u16(set, 0)) {
+				lig := sub(set, int(u16(set, 2+2*n)))
+				input := []uint16{gid}
+				for comp := range int(u16(lig, 2)) - 1 {
+					input = append(input, u16(lig, 4+2*comp))
+				}
+				add(input, []uint16{u16(lig, 0)})
+			}
+
+		case SubstReverse:
+			back := int(u16(st, 4))
+			ahead := 6 + 2*back
+			glyphs := ahead + 2 + 2*int(u16(st, ahead))
+			add([]uint16{gid}, []uint16{u16(st, glyphs+2+2*idx)})
+			ret[len(ret)-1].Contextual = true
+		}
+	}
+	return ret
+}
+
+func singleSubst(st []byte, gid uint16, idx int) uint16 {
+	if u16(st, 0) == 1 {
+		return gid + u16(st, 4)
+	}
+	return u16(st, 6+2*idx)
+}
+
+// Runs the lookups of `features` over every glyph of `glyphs` in lookup
+// list order. Unlike a shaper it doesn't limit features to joining positions
+// or languages, see MaskedFeatures. Alternates pick the first choice. Lookup
+// flags, eg ignoring marks, aren't honoured.
+func (l *Layout) Apply(features []int, glyphs []uint16) []uint16 {
+	lookups := []int{}
+	for _, feat := range features {
+		if feat >= 0 && feat < len(l.Features) {
+			lookups = append(lookups, l.Features[feat].Lookups...)
+		}
+	}
+	slices.Sort(lookups)
+	lookups = slices.Compact(lookups)
+
+	glyphs = slices.Clone(glyphs)
+	for _, lookup := range lookups {
+		if lookup >= len(l.Lookups) {
+			continue
+		}
+
+		if l.Lookups[lookup].Type == SubstReverse {
+			for pos := len(glyphs) - 1; pos >= 0; pos-- {
+				glyphs, _, _ = l.applyAt(lookup, glyphs, pos, 0)
+			}
+			continue
+		}
+
+		for pos := 0; pos < len(glyphs); {
+			var n int
+			var ok bool
+			glyphs, n, ok = l.applyAt(lookup, glyphs, pos, 0)
+			if !ok {
+				n = 1
+			}
+			pos += n
+		}
+	}
+	return glyphs
+}
+
+// Applies a lookup at a single position. Returns the number of glyphs it
+// produced, which a following lookup position should skip.
+func (l *Layout) applyAt(lookup int, glyphs []uint16, pos, depth int) ([]uint16, int, bool) {
+	if lookup < 0 || lookup >= len(l.Lookups) || pos >= len(glyphs) || depth > 8 {
+		return glyphs, 0, false
+	}
+	kind := l.Lookups[lookup].Type
+	gid := glyphs[pos]
+
+	for _, st := range l.Lookups[lookup].Subtables {
+		if kind == SubstContext || kind == SubstChained {
+			n, records, ok := matchContext(st, kind == SubstChained, glyphs, pos)
+			if !ok {
+				continue
+			}
+
+			end := pos + n
+			for _, rec := range records {
+				at := pos + rec.Sequence
+				if at >= end {
+					continue
+				}
+				before := len(glyphs)
+				glyphs, _, _ = l.applyAt(rec.Lookup, glyphs, at, depth+1)
+				end += len(glyphs) - before
+			}
+			return glyphs, max(end-pos, 0), true
+		}
+
+		idx := coverageIndex(sub(st, int(u16(st, 2))), gid)
+		if idx < 0 {
+			continue
+		}
+
+		switch kind {
+		case SubstSingle:
+			glyphs[pos] = singleSubst(st, gid, idx)
+			return glyphs, 1, true
+
+		case SubstMultiple:
+			seq := sub(st, int(u16(st, 6+2*idx)))
+			out := make([]uint16, u16(seq, 0))
+			for n := range out {
+				out[n] = u16(seq, 2+2*n)
+			}
+			return slices.Replace(glyphs, pos, pos+1, out...), len(out), true
+
+		case SubstAlternate:
+			set := sub(st, int(u16(st, 6+2*idx)))
+			if u16(set, 0) > 0 {
+				glyphs[pos] = u16(set, 2)
+			}
+			return glyphs, 1, true
+
+		case SubstLigature:
+			set := sub(st, int(u16(st, 6+2*idx)))
+		ligatures:
+			for n := range int(u16(set, 0)) {
+				lig := sub(set, int(u16(set, 2+2*n)))
+				count := int(u16(lig, 2))
+				if count < 1 || pos+count > len(glyphs) {
+					continue
+				}
+				for comp := range count - 1 {
+					if glyphs[pos+1+comp] != u16(lig, 4+2*comp) {
+						continue ligatures
+					}
+				}
+				return slices.Replace(glyphs, pos, pos+count, u16(lig, 0)), 1, true
+			}
+
+		case SubstReverse:
+			back := int(u16(st, 4))
+			ahead := 6 + 2*back
+			aheadCount := int(u16(st, ahead))
+			subst := ahead + 2 + 2*aheadCount
+
+			matches := func(off, count, start, dir int) bool {
+				for n := range count {
+					at := start + dir*n
+					if at < 0 || at >= len(glyphs) {
+						return false
+					}
+					if coverageIndex(sub(st, int(u16(st, off+2*n))), glyphs[at]) < 0 {
+						return false
+					}
+				}
+				return true
+			}
+			if matches(6, back, pos-1, -1) && matches(ahead+2, aheadCount, pos+1, 1) {
+				glyphs[pos] = u16(st, subst+2+2*idx)
+				return glyphs, 1, true
+			}
+		}
+	}
+	return glyphs, 0, false
+}
@@ -0,0 +1,467 @@
+package sfnt
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+func unpackPoints(b []byte, off int) ([]int, int) {
+	count := int(u8(b, off))
+	off++
+	if count&0x80 != 0 {
+		count = (count&0x7F)<<8 | int(u8(b, off))
+		off++
+	}
+	// Zero means every point of the glyph
+	if count == 0 {
+		return nil, off
+	}
+
+	ret := make([]int, 0, count)
+	last := 0
+	for len(ret) < count && off < len(b) {
+		ctrl := u8(b, off)
+		off++
+		for range int(ctrl&0x7F) + 1 {
+			if ctrl&0x80 != 0 {
+				last += int(u16(b, off))
+				off += 2
+			} else {
+				last += int(u8(b, off))
+				off++
+			}
+			ret = append(ret, last)
+		}
+	}
+	return ret, off
+}
+
+func unpackDeltas(b []byte, off, count int) ([]float64, int) {
+	ret := make([]float64, 0, count)
+	for len(ret) < count && off < len(b) {
+		ctrl := u8(b, off)
+		off++
+		for range int(ctrl&0x3F) + 1 {
+			switch {
+			case ctrl&0x80 != 0:
+				ret = append(ret, 0)
+			case ctrl&0x40 != 0:
+				ret = append(ret, float64(i16(b, off)))
+				off += 2
+			default:
+				ret = append(ret, float64(int8(u8(b, off))))
+				off++
+			}
+		}
+	}
+	for len(ret) < count {
+		ret = append(ret, 0)
+	}
+	return ret[:count], off
+}
+
+// How much of a tuple variation applies at the normalized coordinates
+func tupleScalar(coords, peak, start, end []float64) float64 {
+	scalar := 1.0
+	for idx, p := range peak {
+		c := 0.0
+		if idx < len(coords) {
+			c = coords[idx]
+		}
+		if p == 0 {
+			continue
+		}
+		if c == 0 {
+			return 0
+		}
+
+		if start != nil {
+			s, e := start[idx], end[idx]
+			// Invalid regions are ignored for this axis
+			if s > p || p > e || (s < 0 && e > 0) {
+				continue
+			}
+			if c < s || c > e {
+				return 0
+			}
+			switch {
+			case c < p:
+				scalar *= (c - s) / (p - s)
+			case c > p:
+				scalar *= (e - c) / (e - p)
+			}
+			continue
+		}
+
+		if c < min(0, p) || c > max(0, p) {
+			return 0
+		}
+		scalar *= c / p
+	}
+	return scalar
+}
+
+func iupValue(x, x1, x2, d1, d2 float64) float64 {
+	if x1 == x2 {
+		if d1 == d2 {
+			return d1
+		}
+		return 0
+	}
+	if x1 > x2 {
+		x1, x2, d1, d2 = x2, x1, d2, d1
+	}
+	switch {
+	case x <= x1:
+		return d1
+	case x >= x2:
+		return d2
+	}
+	return d1 + (x-x1)*(d2-d1)/(x2-x1)
+}
+
+// Infers the deltas of the points a tuple doesn't list from their
+// neighbours on the same contour
+func iup(g *glyfGlyph, deltas []point, touched []bool) {
+	start := 0
+	for _, end := range g.contours {
+		refs := []int{}
+		for idx := start; idx <= end && idx < len(touched); idx++ {
+			if touched[idx] {
+				refs = append(refs, idx)
+			}
+		}
+
+		for n, ref := range refs {
+			next := refs[(n+1)%len(refs)]
+			for idx := ref + 1; ; idx++ {
+				if idx > end {
+					idx = start
+				}
+				if idx == next {
+					break
+				}
+				p, p1, p2 := g.points[idx], g.points[ref], g.points[next]
+				d1, d2 := deltas[ref], deltas[next]
+				deltas[idx].x = iupValue(p.x, p1.x, p2.x, d1.x, d2.x)
+				deltas[idx].y = iupValue(p.y, p1.y, p2.y, d1.y, d2.y)
+			}
+		}
+		start = end + 1
+	}
+}
+
+// Summed gvar deltas of a glyph's points, followed by the four phantom
+// points
+func gvarDeltas(gvar []byte, gid int, g *glyfGlyph, coords []float64) []point {
+	total := g.numPoints() + 4
+	ret := make([]point, total)
+
+	axisCount := int(u16(gvar, 4))
+	shared := int(u32(gvar, 8))
+	base := int(u32(gvar, 16))
+	offset := func(gid int) int {
+		if u16(gvar, 14)&0x1 != 0 {
+			return int(u32(gvar, 20+4*gid))
+		}
+		return 2 * int(u16(gvar, 20+2*gid))
+	}
+	if gid >= int(u16(gvar, 12)) {
+		return ret
+	}
+	lo, hi := base+offset(gid), base+offset(gid+1)
+	if lo >= hi || hi > len(gvar) {
+		return ret
+	}
+	data := gvar[lo:hi]
+
+	tuple := func(off int) []float64 {
+		ret := make([]float64, axisCount)
+		for idx := range ret {
+			ret[idx] = f2dot14(data, off+2*idx)
+		}
+		return ret
+	}
+
+	count := u16(data, 0)
+	serial := int(u16(data, 2))
+	var sharedPoints []int
+	if count&0x8000 != 0 {
+		sharedPoints, serial = unpackPoints(data, serial)
+	}
+
+	header := 4
+	for range count & 0x0FFF {
+		size, index := int(u16(data, header)), u16(data, header+2)
+		header += 4
+
+		var peak, start, end []float64
+		if index&0x8000 != 0 {
+			peak = tuple(header)
+			header += 2 * axisCount
+		} else {
+			off := shared + 2*axisCount*int(index&0x0FFF)
+			peak = make([]float64, axisCount)
+			for idx := range peak {
+				peak[idx] = f2dot14(gvar, off+2*idx)
+			}
+		}
+		if index&0x4000 != 0 {
+			start, end = tuple(header), tuple(header+2*axisCount)
+			header += 4 * axisCount
+		}
+
+		next := serial + size
+		scalar := tupleScalar(coords, peak, start, end)
+		if scalar == 0 {
+			serial = next
+			continue
+		}
+
+		points, off := sharedPoints, serial
+		if index&0x2000 != 0 {
+			points, off = unpackPoints(data, off)
+		}
+		num := len(points)
+		if points == nil {
+			num = total
+		}
+		xs, off := unpackDeltas(data, off, num)
+		ys, _ := unpackDeltas(data, off, num)
+		serial = next
+
+		if points == nil {
+			for idx := range total {
+				ret[idx].x += scalar * xs[idx]
+				ret[idx].y += scalar * ys[idx]
+			}
+			continue
+		}
+
+		deltas := make([]point, total)
+		touched := make([]bool, total)
+		for idx, pt := range points {
+			if pt < total {
+				deltas[pt] = point{xs[idx], ys[idx]}
+				touched[pt] = true
+			}
+		}
+		if g.components == nil {
+			iup(g, deltas, touched)
+		}
+		for idx := range total {
+			ret[idx].x += scalar * deltas[idx].x
+			ret[idx].y += scalar * deltas[idx].y
+		}
+	}
+	return ret
+}
+
+// Tables copied verbatim into an instance, the variation tables are left
+// out on purpose
+var instanceTables = []string{
+	"OS/2", "cmap", "cvt ", "fpgm", "gasp", "GDEF", "GPOS", "GSUB", "kern",
+	"maxp", "post", "prep", "COLR", "CPAL", "meta",
+}
+
+// Builds a static TrueType font of a variable font at the user space
+// coordinates, in the order of Axes. Only the outlines and advance widths
+// are varied, GPOS and the metrics tables keep their default values. The
+// family is renamed to `family` so the instance can be registered alongside
+// the original.
+func (f *Font) Instance(coords []float64, family string) ([]byte, error) {
+	axes := f.Axes()
+	if len(axes) == 0 {
+		return nil, ErrMissing
+	}
+	gvar := f.Table("gvar")
+	// CFF2 outlines aren't supported
+	if !f.HasTable("glyf") {
+		return nil, ErrFormat
+	}
+	head, hhea := slices.Clone(f.Table("head")), slices.Clone(f.Table("hhea"))
+	if len(head) < 54 || len(hhea) < 36 {
+		return nil, ErrTruncated
+	}
+	norm := f.normalize(axes, coords)
+
+	be := binary.BigEndian
+	var newGlyf, newLoca, newHmtx []byte
+	maxAdvance := 0
+	for gid := range f.NumGlyphs() {
+		g := parseGlyph(f.glyphData(gid))
+		advance, lsb := f.HMetrics(uint16(gid))
+
+		deltas := gvarDeltas(gvar, gid, &g, norm)
+		n := g.numPoints()
+		for idx := range n {
+			dx, dy := math.Round(deltas[idx].x), math.Round(deltas[idx].y)
+			if g.components == nil {
+				g.points[idx].x = math.Round(g.points[idx].x + dx)
+				g.points[idx].y = math.Round(g.points[idx].y + dy)
+			} else if g.components[idx].flags&0x2 != 0 {
+				g.components[idx].dx += int(dx)
+				g.components[idx].dy += int(dy)
+			}
+		}
+		advance = max(advance+int(math.Round(deltas[n+1].x-deltas[n].x)), 0)
+		maxAdvance = max(maxAdvance, advance)
+
+		encoded := g.encode()
+		if g.components == nil && len(encoded) > 0 {
+			// Keep the origin where it was, relative to the new xMin
+			lsb += int(i16(encoded, 2)) - int(g.xMin)
+		}
+		newLoca = be.AppendUint32(newLoca, uint32(len(newGlyf)))
+		newGlyf = append(newGlyf, encoded...)
+		newHmtx = be.AppendUint16(newHmtx, uint16(advance))
+		newHmtx = be.AppendUint16(newHmtx, uint16(int16(lsb)))
+	}
+	newLoca = be.AppendUint32(newLoca, uint32(len(newGlyf)))
+
+	be.PutUint32(head[8:], 0)
+	be.PutUint16(head[50:], 1)
+	be.PutUint16(hhea[10:], uint16(maxAdvance))
+	be.PutUint16(hhea[34:], uint16(f.NumGlyphs()))
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"hmtx": newHmtx,
+		"glyf": newGlyf,
+		"loca": newLoca,
+		"name": f.renamedNames(family, "Regular"),
+	}
+	for _, tag := range instanceTables {
+		if data := f.Table(tag); len(data) > 0 {
+			tables[tag] = data
+		}
+	}
+
+	for idx, axis := range axes {
+		if axis.Tag == "wght" && idx < len(coords) && len(tables["OS/2"]) > 6 {
+			os2 := slices.Clone(tables["OS/2"])
+			be.PutUint16(os2[4:], uint16(min(max(math.Round(coords[idx]), 1), 1000)))
+			tables["OS/2"] = os2
+		}
+	}
+	return Assemble(tables), nil
+}
+
+// A name table of Windows records only, so the original family doesn't
+// linger in a Macintosh record
+func (f *Font) renamedNames(family, style string) []byte {
+	values := map[uint16]string{}
+	for _, rec := range f.NameRecords() {
+		if _, ok := values[rec.ID]; !ok {
+			values[rec.ID] = f.Name(rec.ID)
+		}
+	}
+	for _, id := range []uint16{16, 17, 21, 22, 25} {
+		delete(values, id)
+	}
+	values[1] = family
+	values[2] = style
+	values[4] = family
+	if style != "Regular" {
+		values[4] = family + " " + style
+	}
+	values[6] = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("[](){}<>/%", r) {
+			return '-'
+		}
+		return r
+	}, values[4])
+
+	return NameTable(values)
+}
+
+// A name table of English Windows records for the values by name ID
+func NameTable(values map[uint16]string) []byte {
+	ids := []uint16{}
+	for id := range values {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	be := binary.BigEndian
+	b := be.AppendUint16(nil, 0)
+	b = be.AppendUint16(b, uint16(len(ids)))
+	b = be.AppendUint16(b, uint16(6+12*len(ids)))
+	var storage []byte
+	for _, id := range ids {
+		var value []byte
+		for _, unit := range utf16.Encode([]rune(values[id])) {
+			value = be.AppendUint16(value, unit)
+		}
+		b = be.AppendUint16(b, 3)
+		b = be.AppendUint16(b, 1)
+		b = be.AppendUint16(b, 0x409)
+		b = be.AppendUint16(b, id)
+		b = be.AppendUint16(b, uint16(len(value)))
+		b = be.AppendUint16(b, uint16(len(storage)))
+		storage = append(storage, value...)
+	}
+	return append(b, storage...)
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for off := 0; off < len(b); off += 4 {
+		var word [4]byte
+		copy(word[:], b[off:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// Assembles a font file from its tables, fixing up the head checksum
+func Assemble(tables map[string][]byte) []byte {
+	tags := []string{}
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	be := binary.BigEndian
+	num := len(tags)
+	selector := bits.Len(uint(num)) - 1
+	version := uint32(0x00010000)
+	if tables["CFF "] != nil || tables["CFF2"] != nil {
+		version = 0x4F54544F // OTTO
+	}
+	b := be.AppendUint32(nil, version)
+	b = be.AppendUint16(b, uint16(num))
+	b = be.AppendUint16(b, uint16(16<<selector))
+	b = be.AppendUint16(b, uint16(selector))
+	b = be.AppendUint16(b, uint16(num*16-16<<selector))
+
+	off := 12 + 16*num
+	headAt := -1
+	var body []byte
+	for _, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			headAt = off + 8
+		}
+		b = append(b, tag...)
+		b = be.AppendUint32(b, checksum(data))
+		b = be.AppendUint32(b, uint32(off))
+		b = be.AppendUint32(b, uint32(len(data)))
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+		off = 12 + 16*num + len(body)
+	}
+	b = append(b, body...)
+
+	if headAt >= 0 {
+		be.PutUint32(b[headAt:], 0xB1B0AFBA-checksum(b))
+	}
+	return b
+}
@@ -0,0 +1,203 @@
+package sfnt
+
+import "math/bits"
+
+type KernPair struct {
+	Left, Right uint16
+	// Font units, negative moves the glyphs closer
+	Value  int
+	Source string
+}
+
+type kerning struct {
+	pairs   [][]byte
+	classic map[uint32]int
+}
+
+func (f *Font) kerning() *kerning {
+	f.mut.Lock()
+	cached := f.kern
+	f.mut.Unlock()
+	if cached != nil {
+		return cached
+	}
+
+	ret := &kerning{}
+	if gpos, err := f.GPOS(); err == nil {
+		seen := map[int]bool{}
+		for _, feat := range gpos.Features {
+			if feat.Tag != "kern" {
+				continue
+			}
+			for _, lookup := range feat.Lookups {
+				if seen[lookup] || lookup >= len(gpos.Lookups) {
+					continue
+				}
+				seen[lookup] = true
+				if gpos.Lookups[lookup].Type == 2 {
+					ret.pairs = append(ret.pairs, gpos.Lookups[lookup].Subtables...)
+				}
+			}
+		}
+	}
+	ret.classic = parseKern(f.Table("kern"))
+
+	f.mut.Lock()
+	f.kern = ret
+	f.mut.Unlock()
+	return ret
+}
+
+// Horizontal format 0 subtables of the kern table, in both the Microsoft
+// and the Apple layout
+func parseKern(b []byte) map[uint32]int {
+	ret := map[uint32]int{}
+
+	off, count := 4, int(u16(b, 2))
+	apple := u16(b, 0) == 1
+	if apple {
+		off, count = 8, int(u32(b, 4))
+	}
+
+	for range count {
+		var length, format int
+		var coverage uint16
+		var pairs int
+		if apple {
+			length = int(u32(b, off))
+			coverage = u16(b, off+4)
+			format = int(coverage & 0xFF)
+			pairs = off + 8
+			// Vertical or cross-stream
+			if coverage&0xC000 != 0 {
+				format = -1
+			}
+		} else {
+			length = int(u16(b, off+2))
+			coverage = u16(b, off+4)
+			format = int(coverage >> 8)
+			pairs = off + 6
+			if coverage&0x1 == 0 || coverage&0x4 != 0 {
+				format = -1
+			}
+		}
+		if length <= 0 {
+			break
+		}
+
+		if format == 0 {
+			for idx := range int(u16(b, pairs)) {
+				rec := pairs + 8 + 6*idx
+				key := uint32(u16(b, rec))<<16 | uint32(u16(b, rec+2))
+				ret[key] += int(i16(b, rec+4))
+			}
+		}
+		off += length
+	}
+	return ret
+}
+
+func valueSize(format uint16) int {
+	return 2 * bits.OnesCount16(format&0xFF)
+}
+
+// X advance adjustment of a ValueRecord
+func valueAdvance(b []byte, off int, format uint16) int {
+	if format&0x4 == 0 {
+		return 0
+	}
+	return int(i16(b, off+valueSize(format&0x3)))
+}
+
+// Looks the pair up in the PairPos subtables of the GPOS kern feature, or
+// the kern table if there are none, as shapers do. False if the pair isn't
+// kerned.
+func (f *Font) Kerning(left, right uint16) (KernPair, bool) {
+	kern := f.kerning()
+	if len(kern.pairs) > 0 {
+		return kern.gpos(left, right)
+	}
+	value, ok := kern.classic[uint32(left)<<16|uint32(right)]
+	return KernPair{left, right, value, "kern"}, ok && value != 0
+}
+
+func (k *kerning) gpos(left, right uint16) (KernPair, bool) {
+	pair := KernPair{Left: left, Right: right, Source: "GPOS"}
+
+	for _, st := range k.pairs {
+		idx := coverageIndex(sub(st, int(u16(st, 2))), left)
+		if idx < 0 {
+			continue
+		}
+		format1, format2 := u16(st, 4), u16(st, 6)
+		size1, size2 := valueSize(format1), valueSize(format2)
+
+		switch u16(st, 0) {
+		case 1:
+			if idx >= int(u16(st, 8)) {
+				continue
+			}
+			set := sub(st, int(u16(st, 10+2*idx)))
+			stride := 2 + size1 + size2
+			lo, hi := 0, int(u16(set, 0))
+			for lo < hi {
+				mid := (lo + hi) / 2
+				rec := 2 + stride*mid
+				second := u16(set, rec)
+				switch {
+				case second == right:
+					pair.Value = valueAdvance(set, rec+2, format1)
+					return pair, pair.Value != 0
+				case second < right:
+					lo = mid + 1
+				default:
+					hi = mid
+				}
+			}
+
+		case 2:
+			// Class 0 covers every glyph, so the first subtable
+			// covering `left` is the last one consulted
+			class1 := classOf(sub(st, int(u16(st, 8))), left)
+			class2 := classOf(sub(st, int(u16(st, 10))), right)
+			count1, count2 := int(u16(st, 12)), int(u16(st, 14))
+			if class1 >= count1 || class2 >= count2 {
+				continue
+			}
+			rec := 16 + (class1*count2+class2)*(size1+size2)
+			pair.Value = valueAdvance(st, rec, format1)
+			return pair, pair.Value != 0
+		}
+	}
+	return pair, false
+}
+
+// Every kerned pair with `gid` on either side, from both GPOS and the kern
+// table. GPOS pairs are found by checking against all glyphs of the font.
+func (f *Font) KernPairs(gid uint16) (asLeft, asRight []KernPair) {
+	kern := f.kerning()
+	if len(kern.pairs) > 0 {
+		for other := range f.NumGlyphs() {
+			if pair, ok := kern.gpos(gid, uint16(other)); ok {
+				asLeft = append(asLeft, pair)
+			}
+			if pair, ok := kern.gpos(uint16(other), gid); ok {
+				asRight = append(asRight, pair)
+			}
+		}
+	}
+
+	for key, value := range kern.classic {
+		pair := KernPair{uint16(key >> 16), uint16(key), value, "kern"}
+		if value == 0 {
+			continue
+		}
+		if pair.Left == gid {
+			asLeft = append(asLeft, pair)
+		}
+		if pair.Right == gid {
+			asRight = append(asRight, pair)
+		}
+	}
+	return asLeft, asRight
+}
@@ -0,0 +1,460 @@
+package sfnt
+
+// Common structure of the GSUB and GPOS tables
+type Layout struct {
+	data      []byte
+	extension int
+
+	Scripts  []Script
+	Features []Feature
+	Lookups  []Lookup
+}
+
+type Script struct {
+	Tag   string
+	Langs []LangSys
+}
+
+// The default language system of a script has the tag "dflt"
+type LangSys struct {
+	Tag      string
+	Required int
+	Features []int
+}
+
+type Feature struct {
+	Tag     string
+	Lookups []int
+	// Name ID of the UI label of ssXX and cvXX features, 0 if not given
+	UIName uint16
+}
+
+type Lookup struct {
+	Type      int
+	Flag      uint16
+	Subtables [][]byte
+}
+
+type lookupRecord struct {
+	Sequence int
+	Lookup   int
+}
+
+func (f *Font) GSUB() (*Layout, error) {
+	return parseLayout(f.Table("GSUB"), 7)
+}
+
+func (f *Font) GPOS() (*Layout, error) {
+	return parseLayout(f.Table("GPOS"), 9)
+}
+
+func parseLayout(b []byte, extension int) (*Layout, error) {
+	if len(b) < 10 {
+		return nil, ErrMissing
+	}
+	if u16(b, 0) != 1 {
+		return nil, ErrFormat
+	}
+
+	ret := &Layout{data: b, extension: extension}
+
+	scripts := int(u16(b, 4))
+	for idx := range int(u16(b, scripts)) {
+		rec := scripts + 2 + 6*idx
+		off := scripts + int(u16(b, rec+4))
+		script := Script{Tag: tag(b, rec)}
+
+		if def := u16(b, off); def != 0 {
+			script.Langs = append(script.Langs, parseLangSys(b, off+int(def), "dflt"))
+		}
+		for lang := range int(u16(b, off+2)) {
+			lrec := off + 4 + 6*lang
+			script.Langs = append(script.Langs,
+				parseLangSys(b, off+int(u16(b, lrec+4)), tag(b, lrec)),
+			)
+		}
+		ret.Scripts = append(ret.Scripts, script)
+	}
+
+	features := int(u16(b, 6))
+	for idx := range int(u16(b, features)) {
+		rec := features + 2 + 6*idx
+		off := features + int(u16(b, rec+4))
+		feat := Feature{Tag: tag(b, rec)}
+
+		for n := range int(u16(b, off+2)) {
+			feat.Lookups = append(feat.Lookups, int(u16(b, off+4+2*n)))
+		}
+		params := int(u16(b, off))
+		prefix := feat.Tag[:min(len(feat.Tag), 2)]
+		if params != 0 && (prefix == "ss" || prefix == "cv") {
+			// Both parameter layouts start with a version or format
+			feat.UIName = u16(b, off+params+2)
+		}
+		ret.Features = append(ret.Features, feat)
+	}
+
+	lookups := int(u16(b, 8))
+	for idx := range int(u16(b, lookups)) {
+		off := lookups + int(u16(b, lookups+2+2*idx))
+		lookup := Lookup{
+			Type: int(u16(b, off)),
+			Flag: u16(b, off+2),
+		}
+
+		for n := range int(u16(b, off+4)) {
+			table := sub(b, off+int(u16(b, off+6+2*n)))
+			if lookup.Type == extension {
+				lookup.Type = int(u16(table, 2))
+				table = sub(table, int(u32(table, 4)))
+			}
+			lookup.Subtables = append(lookup.Subtables, table)
+		}
+		ret.Lookups = append(ret.Lookups, lookup)
+	}
+
+	return ret, nil
+}
+
+func parseLangSys(b []byte, off int, name string) LangSys {
+	ret := LangSys{Tag: name, Required: -1}
+	if req := u16(b, off+2); req != 0xFFFF {
+		ret.Required = int(req)
+	}
+	for idx := range int(u16(b, off+4)) {
+		ret.Features = append(ret.Features, int(u16(b, off+6+2*idx)))
+	}
+	return ret
+}
+
+// Indexes of the features available to a script and language, falling back
+// to the default language and then the DFLT script like shapers do
+func (l *Layout) FeaturesFor(script, lang string) []int {
+	find := func(script string) *Script {
+		for idx := range l.Scripts {
+			if l.Scripts[idx].Tag == script {
+				return &l.Scripts[idx]
+			}
+		}
+		return nil
+	}
+
+	found := find(script)
+	if found == nil {
+		found = find("DFLT")
+	}
+	if found == nil {
+		found = find("latn")
+	}
+	if found == nil {
+		return nil
+	}
+
+	var sys *LangSys
+	for idx := range found.Langs {
+		if found.Langs[idx].Tag == lang {
+			sys = &found.Langs[idx]
+		}
+	}
+	if sys == nil {
+		for idx := range found.Langs {
+			if found.Langs[idx].Tag == "dflt" {
+				sys = &found.Langs[idx]
+			}
+		}
+	}
+	if sys == nil {
+		return nil
+	}
+
+	ret := append([]int{}, sys.Features...)
+	if sys.Required >= 0 {
+		ret = append(ret, sys.Required)
+	}
+	return ret
+}
+
+// Index of `gid` in a Coverage table, -1 if not covered
+func coverageIndex(b []byte, gid uint16) int {
+	switch u16(b, 0) {
+	case 1:
+		lo, hi := 0, int(u16(b, 2))
+		for lo < hi {
+			mid := (lo + hi) / 2
+			g := u16(b, 4+2*mid)
+			switch {
+			case g == gid:
+				return mid
+			case g < gid:
+				lo = mid + 1
+			default:
+				hi = mid
+			}
+		}
+	case 2:
+		lo, hi := 0, int(u16(b, 2))
+		for lo < hi {
+			mid := (lo + hi) / 2
+			rec := 4 + 6*mid
+			start, end := u16(b, rec), u16(b, rec+2)
+			switch {
+			case gid < start:
+				hi = mid
+			case gid > end:
+				lo = mid + 1
+			default:
+				return int(u16(b, rec+4)) + int(gid-start)
+			}
+		}
+	}
+	return -1
+}
+
+// Every glyph of a Coverage table in coverage index order
+func coverageGlyphs(b []byte) []uint16 {
+	ret := []uint16{}
+	switch u16(b, 0) {
+	case 1:
+		for idx := range int(u16(b, 2)) {
+			ret = append(ret, u16(b, 4+2*idx))
+		}
+	case 2:
+		for idx := range int(u16(b, 2)) {
+			rec := 4 + 6*idx
+			for g := int(u16(b, rec)); g <= int(u16(b, rec+2)); g++ {
+				ret = append(ret, uint16(g))
+			}
+		}
+	}
+	return ret
+}
+
+func classOf(b []byte, gid uint16) int {
+	switch u16(b, 0) {
+	case 1:
+		start := u16(b, 2)
+		if gid >= start && int(gid-start) < int(u16(b, 4)) {
+			return int(u16(b, 6+2*int(gid-start)))
+		}
+	case 2:
+		for idx := range int(u16(b, 2)) {
+			rec := 4 + 6*idx
+			if gid >= u16(b, rec) && gid <= u16(b, rec+2) {
+				return int(u16(b, rec+4))
+			}
+		}
+	}
+	return 0
+}
+
+// Matches a (chained) sequence context subtable, shared by GSUB types 5 and
+// 6 and GPOS types 7 and 8, against `glyphs` at `pos`. Returns the length of
+// the matched input and the nested lookups to apply to it.
+func matchContext(b []byte, chained bool, glyphs []uint16, pos int) (int, []lookupRecord, bool) {
+	at := func(idx int) (uint16, bool) {
+		if idx < 0 || idx >= len(glyphs) {
+			return 0, false
+		}
+		return glyphs[idx], true
+	}
+
+	// Checks a sequence of glyphs stepping away from `pos` in `dir`
+	check := func(start, dir, count int, match func(n int, g uint16) bool) bool {
+		for n := range count {
+			g, ok := at(start + dir*n)
+			if !ok || !match(n, g) {
+				return false
+			}
+		}
+		return true
+	}
+
+	records := func(src []byte, off, count int) []lookupRecord {
+		ret := make([]lookupRecord, count)
+		for idx := range ret {
+			ret[idx] = lookupRecord{
+				Sequence: int(u16(src, off+4*idx)),
+				Lookup:   int(u16(src, off+4*idx+2)),
+			}
+		}
+		return ret
+	}
+
+	switch u16(b, 0) {
+	case 1, 2:
+		format := u16(b, 0)
+		cov := coverageIndex(sub(b, int(u16(b, 2))), glyphs[pos])
+		if cov < 0 {
+			return 0, nil, false
+		}
+
+		var backDef, inputDef, aheadDef []byte
+		sets := 4
+		setIdx := cov
+		if format == 2 {
+			if chained {
+				backDef = sub(b, int(u16(b, 4)))
+				inputDef = sub(b, int(u16(b, 6)))
+				aheadDef = sub(b, int(u16(b, 8)))
+				sets = 10
+			} else {
+				inputDef = sub(b, int(u16(b, 4)))
+				sets = 6
+			}
+			setIdx = classOf(inputDef, glyphs[pos])
+		}
+		if setIdx >= int(u16(b, sets)) {
+			return 0, nil, false
+		}
+		setOff := int(u16(b, sets+2+2*setIdx))
+		if setOff == 0 {
+			return 0, nil, false
+		}
+		set := sub(b, setOff)
+
+		// Rules hold glyph IDs in format 1 and classes in format 2
+		matcher := func(def []byte) func(int, uint16, uint16) bool {
+			return func(_ int, want, g uint16) bool {
+				if format == 1 {
+					return want == g
+				}
+				return int(want) == classOf(def, g)
+			}
+		}
+
+		for rule := range int(u16(set, 0)) {
+			r := sub(set, int(u16(set, 2+2*rule)))
+			off := 0
+			backCount := 0
+			if chained {
+				backCount = int(u16(r, 0))
+				back := 2
+				if !check(pos-1, -1, backCount, func(n int, g uint16) bool {
+					return matcher(backDef)(n, u16(r, back+2*n), g)
+				}) {
+					continue
+				}
+				off = back + 2*backCount
+			}
+
+			inputCount := int(u16(r, off))
+			var input, substCount, recs int
+			if chained {
+				input = off + 2
+				ahead := input + 2*(inputCount-1)
+				aheadCount := int(u16(r, ahead))
+				if !check(pos+inputCount, 1, aheadCount, func(n int, g uint16) bool {
+					return matcher(aheadDef)(n, u16(r, ahead+2+2*n), g)
+				}) {
+					continue
+				}
+				substCount = int(u16(r, ahead+2+2*aheadCount))
+				recs = ahead + 4 + 2*aheadCount
+			} else {
+				substCount = int(u16(r, off+2))
+				input = off + 4
+				recs = input + 2*(inputCount-1)
+			}
+
+			if inputCount < 1 || !check(pos+1, 1, inputCount-1, func(n int, g uint16) bool {
+				return matcher(inputDef)(n, u16(r, input+2*n), g)
+			}) {
+				continue
+			}
+
+			return inputCount, records(r, recs, substCount), true
+		}
+
+	case 3:
+		covered := func(off int) func(int, uint16) bool {
+			return func(n int, g uint16) bool {
+				return coverageIndex(sub(b, int(u16(b, off+2*n))), g) >= 0
+			}
+		}
+
+		if !chained {
+			inputCount := int(u16(b, 2))
+			substCount := int(u16(b, 4))
+			if inputCount < 1 || !check(pos, 1, inputCount, covered(6)) {
+				return 0, nil, false
+			}
+			return inputCount, records(b, 6+2*inputCount, substCount), true
+		}
+
+		backCount := int(u16(b, 2))
+		input := 4 + 2*backCount
+		inputCount := int(u16(b, input))
+		ahead := input + 2 + 2*inputCount
+		aheadCount := int(u16(b, ahead))
+		subst := ahead + 2 + 2*aheadCount
+
+		if inputCount < 1 ||
+			!check(pos-1, -1, backCount, covered(4)) ||
+			!check(pos, 1, inputCount, covered(input+2)) ||
+			!check(pos+inputCount, 1, aheadCount, covered(ahead+2)) {
+			return 0, nil, false
+		}
+		return inputCount, records(b, subst+2, int(u16(b, subst))), true
+	}
+
+	return 0, nil, false
+}
+
+// Every lookup a (chained) sequence context subtable may invoke
+func contextLookups(b []byte, chained bool) []int {
+	seen := map[int]bool{}
+	ret := []int{}
+	add := func(r []byte, off, count int) {
+		for idx := range count {
+			lookup := int(u16(r, off+4*idx+2))
+			if !seen[lookup] {
+				seen[lookup] = true
+				ret = append(ret, lookup)
+			}
+		}
+	}
+
+	switch u16(b, 0) {
+	case 1, 2:
+		sets := 4
+		if u16(b, 0) == 2 {
+			sets = 6
+			if chained {
+				sets = 10
+			}
+		}
+		for idx := range int(u16(b, sets)) {
+			setOff := int(u16(b, sets+2+2*idx))
+			if setOff == 0 {
+				continue
+			}
+			set := sub(b, setOff)
+			for rule := range int(u16(set, 0)) {
+				r := sub(set, int(u16(set, 2+2*rule)))
+				if !chained {
+					count := int(u16(r, 0))
+					add(r, 4+2*(count-1), int(u16(r, 2)))
+					continue
+				}
+				back := int(u16(r, 0))
+				input := 2 + 2*back
+				ahead := input + 2 + 2*(int(u16(r, input))-1)
+				subst := ahead + 2 + 2*int(u16(r, ahead))
+				add(r, subst+2, int(u16(r, subst)))
+			}
+		}
+
+	case 3:
+		if !chained {
+			count := int(u16(b, 2))
+			add(b, 6+2*count, int(u16(b, 4)))
+			break
+		}
+		input := 4 + 2*int(u16(b, 2))
+		ahead := input + 2 + 2*int(u16(b, input))
+		subst := ahead + 2 + 2*int(u16(b, ahead))
+		add(b, subst+2, int(u16(b, subst)))
+	}
+
+	return ret
+}
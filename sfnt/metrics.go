@@ -0,0 +1,330 @@
+package sfnt
+
+import (
+	"time"
+)
+
+type Head struct {
+	Revision   float64
+	Flags      uint16
+	UnitsPerEm uint16
+	Created    time.Time
+	Modified   time.Time
+	XMin, YMin int16
+	XMax, YMax int16
+	MacStyle   uint16
+	// 0 for 16 bit loca offsets, 1 for 32 bit
+	IndexToLocFormat int16
+}
+
+type Hhea struct {
+	Ascender        int16
+	Descender       int16
+	LineGap         int16
+	AdvanceWidthMax uint16
+	MinLeftBearing  int16
+	MinRightBearing int16
+	XMaxExtent      int16
+	NumberOfMetrics uint16
+}
+
+type OS2 struct {
+	Version       uint16
+	AvgCharWidth  int16
+	WeightClass   uint16
+	WidthClass    uint16
+	FsType        uint16
+	FsSelection   uint16
+	Vendor        string
+	Panose        [10]byte
+	UnicodeRange  [4]uint32
+	CodePageRange [2]uint32
+	FirstChar     uint16
+	LastChar      uint16
+	TypoAscender  int16
+	TypoDescender int16
+	TypoLineGap   int16
+	WinAscent     uint16
+	WinDescent    uint16
+	XHeight       int16
+	CapHeight     int16
+}
+
+func fixed(b []byte, off int) float64 {
+	return float64(i32(b, off)) / 65536
+}
+
+// Seconds since midnight, January 1st 1904
+func longDateTime(b []byte, off int) time.Time {
+	secs := int64(u32(b, off))<<32 | int64(u32(b, off+4))
+	return time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(secs) * time.Second)
+}
+
+func (f *Font) Head() (Head, error) {
+	b := f.Table("head")
+	if len(b) < 54 {
+		return Head{}, ErrMissing
+	}
+	return Head{
+		Revision:         fixed(b, 4),
+		Flags:            u16(b, 16),
+		UnitsPerEm:       u16(b, 18),
+		Created:          longDateTime(b, 20),
+		Modified:         longDateTime(b, 28),
+		XMin:             i16(b, 36),
+		YMin:             i16(b, 38),
+		XMax:             i16(b, 40),
+		YMax:             i16(b, 42),
+		MacStyle:         u16(b, 44),
+		IndexToLocFormat: i16(b, 50),
+	}, nil
+}
+
+func (f *Font) Hhea() (Hhea, error) {
+	b := f.Table("hhea")
+	if len(b) < 36 {
+		return Hhea{}, ErrMissing
+	}
+	return Hhea{
+		Ascender:        i16(b, 4),
+		Descender:       i16(b, 6),
+		LineGap:         i16(b, 8),
+		AdvanceWidthMax: u16(b, 10),
+		MinLeftBearing:  i16(b, 12),
+		MinRightBearing: i16(b, 14),
+		XMaxExtent:      i16(b, 16),
+		NumberOfMetrics: u16(b, 34),
+	}, nil
+}
+
+// Fields past the length of older versions are left zero
+func (f *Font) OS2() (OS2, error) {
+	b := f.Table("OS/2")
+	if len(b) < 78 {
+		return OS2{}, ErrMissing
+	}
+
+	ret := OS2{
+		Version:       u16(b, 0),
+		AvgCharWidth:  i16(b, 2),
+		WeightClass:   u16(b, 4),
+		WidthClass:    u16(b, 6),
+		FsType:        u16(b, 8),
+		Vendor:        tag(b, 58),
+		FsSelection:   u16(b, 62),
+		FirstChar:     u16(b, 64),
+		LastChar:      u16(b, 66),
+		TypoAscender:  i16(b, 68),
+		TypoDescender: i16(b, 70),
+		TypoLineGap:   i16(b, 72),
+		WinAscent:     u16(b, 74),
+		WinDescent:    u16(b, 76),
+	}
+	copy(ret.Panose[:], b[32:42])
+	for idx := range ret.UnicodeRange {
+		ret.UnicodeRange[idx] = u32(b, 42+4*idx)
+	}
+	if ret.Version >= 1 {
+		ret.CodePageRange = [2]uint32{u32(b, 78), u32(b, 82)}
+	}
+	if ret.Version >= 2 {
+		ret.XHeight = i16(b, 86)
+		ret.CapHeight = i16(b, 88)
+	}
+	return ret, nil
+}
+
+// Names of the set bits of ulUnicodeRange1-4
+func (o OS2) UnicodeRanges() []string {
+	ret := []string{}
+	for bit := range 128 {
+		if o.UnicodeRange[bit/32]&(1<<(bit%32)) == 0 {
+			continue
+		}
+		if bit < len(unicodeRangeBits) {
+			ret = append(ret, unicodeRangeBits[bit])
+		} else {
+			ret = append(ret, "Reserved")
+		}
+	}
+	return ret
+}
+
+// Names of the set bits of ulCodePageRange1-2
+func (o OS2) CodePages() []string {
+	ret := []string{}
+	for bit := range 64 {
+		if o.CodePageRange[bit/32]&(1<<(bit%32)) == 0 {
+			continue
+		}
+		if name, ok := codePageBits[bit]; ok {
+			ret = append(ret, name)
+		} else {
+			ret = append(ret, "Reserved")
+		}
+	}
+	return ret
+}
+
+// Embedding permissions of fsType. When several usage bits are set the
+// least restrictive one applies.
+func (o OS2) Embedding() []string {
+	ret := []string{}
+	switch {
+	case o.FsType&0x8 != 0:
+		ret = append(ret, "Editable")
+	case o.FsType&0x4 != 0:
+		ret = append(ret, "Preview & Print")
+	case o.FsType&0x2 != 0:
+		ret = append(ret, "Restricted License")
+	default:
+		ret = append(ret, "Installable")
+	}
+	if o.FsType&0x100 != 0 {
+		ret = append(ret, "No subsetting")
+	}
+	if o.FsType&0x200 != 0 {
+		ret = append(ret, "Bitmap embedding only")
+	}
+	return ret
+}
+
+func (o OS2) WeightName() string {
+	names := []string{
+		"Thin", "Extra Light", "Light", "Regular", "Medium",
+		"Semi Bold", "Bold", "Extra Bold", "Black",
+	}
+	idx := (int(o.WeightClass)+50)/100 - 1
+	if idx < 0 || idx >= len(names) {
+		return ""
+	}
+	return names[idx]
+}
+
+func (o OS2) WidthName() string {
+	names := []string{
+		"Ultra Condensed", "Extra Condensed", "Condensed", "Semi Condensed",
+		"Normal", "Semi Expanded", "Expanded", "Extra Expanded", "Ultra Expanded",
+	}
+	if o.WidthClass < 1 || int(o.WidthClass) > len(names) {
+		return ""
+	}
+	return names[o.WidthClass-1]
+}
+
+// Outline or bitmap format going by the tables present
+func (f *Font) Format() string {
+	switch {
+	case f.HasTable("CFF2"):
+		return "OpenType (CFF2)"
+	case f.HasTable("CFF "):
+		return "OpenType (CFF)"
+	case f.HasTable("glyf"):
+		return "TrueType"
+	case f.HasTable("CBDT"), f.HasTable("sbix"), f.HasTable("EBDT"):
+		return "Bitmap only"
+	}
+	return "Unknown"
+}
+
+var NameIDs = map[uint16]string{
+	0:  "Copyright",
+	1:  "Family",
+	2:  "Subfamily",
+	3:  "Unique ID",
+	4:  "Full Name",
+	5:  "Version",
+	6:  "PostScript Name",
+	7:  "Trademark",
+	8:  "Manufacturer",
+	9:  "Designer",
+	10: "Description",
+	11: "Vendor URL",
+	12: "Designer URL",
+	13: "License",
+	14: "License URL",
+	16: "Typographic Family",
+	17: "Typographic Subfamily",
+	18: "Compatible Full Name",
+	19: "Sample Text",
+	20: "PostScript CID Name",
+	21: "WWS Family",
+	22: "WWS Subfamily",
+	23: "Light Background Palette",
+	24: "Dark Background Palette",
+	25: "Variations PostScript Prefix",
+}
+
+var unicodeRangeBits = []string{
+	"Basic Latin", "Latin-1 Supplement", "Latin Extended-A",
+	"Latin Extended-B", "IPA Extensions", "Spacing Modifier Letters",
+	"Combining Diacritical Marks", "Greek and Coptic", "Coptic", "Cyrillic",
+	"Armenian", "Hebrew", "Vai", "Arabic", "NKo", "Devanagari", "Bengali",
+	"Gurmukhi", "Gujarati", "Oriya", "Tamil", "Telugu", "Kannada",
+	"Malayalam", "Thai", "Lao", "Georgian", "Balinese", "Hangul Jamo",
+	"Latin Extended Additional", "Greek Extended", "General Punctuation",
+	"Superscripts And Subscripts", "Currency Symbols",
+	"Combining Diacritical Marks For Symbols", "Letterlike Symbols",
+	"Number Forms", "Arrows", "Mathematical Operators",
+	"Miscellaneous Technical", "Control Pictures",
+	"Optical Character Recognition", "Enclosed Alphanumerics",
+	"Box Drawing", "Block Elements", "Geometric Shapes",
+	"Miscellaneous Symbols", "Dingbats", "CJK Symbols And Punctuation",
+	"Hiragana", "Katakana", "Bopomofo", "Hangul Compatibility Jamo",
+	"Phags-pa", "Enclosed CJK Letters And Months", "CJK Compatibility",
+	"Hangul Syllables", "Non-Plane 0", "Phoenician",
+	"CJK Unified Ideographs", "Private Use Area (plane 0)", "CJK Strokes",
+	"Alphabetic Presentation Forms", "Arabic Presentation Forms-A",
+	"Combining Half Marks", "Vertical Forms", "Small Form Variants",
+	"Arabic Presentation Forms-B", "Halfwidth And Fullwidth Forms",
+	"Specials", "Tibetan", "Syriac", "Thaana", "Sinhala", "Myanmar",
+	"Ethiopic", "Cherokee", "Unified Canadian Aboriginal Syllabics", "Ogham",
+	"Runic", "Khmer", "Mongolian", "Braille Patterns", "Yi Syllables",
+	"Tagalog", "Old Italic", "Gothic", "Deseret",
+	"Byzantine Musical Symbols", "Mathematical Alphanumeric Symbols",
+	"Private Use (plane 15)", "Variation Selectors", "Tags", "Limbu",
+	"Tai Le", "New Tai Lue", "Buginese", "Glagolitic", "Tifinagh",
+	"Yijing Hexagram Symbols", "Syloti Nagri", "Linear B Syllabary",
+	"Ancient Greek Numbers", "Ugaritic", "Old Persian", "Shavian",
+	"Osmanya", "Cypriot Syllabary", "Kharoshthi", "Tai Xuan Jing Symbols",
+	"Cuneiform", "Counting Rod Numerals", "Sundanese", "Lepcha", "Ol Chiki",
+	"Saurashtra", "Kayah Li", "Rejang", "Cham", "Ancient Symbols",
+	"Phaistos Disc", "Carian", "Domino Tiles",
+}
+
+var codePageBits = map[int]string{
+	0:  "1252 Latin 1",
+	1:  "1250 Latin 2: Eastern Europe",
+	2:  "1251 Cyrillic",
+	3:  "1253 Greek",
+	4:  "1254 Turkish",
+	5:  "1255 Hebrew",
+	6:  "1256 Arabic",
+	7:  "1257 Windows Baltic",
+	8:  "1258 Vietnamese",
+	16: "874 Thai",
+	17: "932 JIS/Japan",
+	18: "936 Chinese: Simplified",
+	19: "949 Korean Wansung",
+	20: "950 Chinese: Traditional",
+	21: "1361 Korean Johab",
+	29: "Macintosh Character Set (US Roman)",
+	30: "OEM Character Set",
+	31: "Symbol Character Set",
+	48: "869 IBM Greek",
+	49: "866 MS-DOS Russian",
+	50: "865 MS-DOS Nordic",
+	51: "864 Arabic",
+	52: "863 MS-DOS Canadian French",
+	53: "862 Hebrew",
+	54: "861 MS-DOS Icelandic",
+	55: "860 MS-DOS Portuguese",
+	56: "857 IBM Turkish",
+	57: "855 IBM Cyrillic; primarily Russian",
+	58: "852 Latin 2",
+	59: "775 MS-DOS Baltic",
+	60: "737 Greek; former 437 G",
+	61: "708 Arabic; ASMO 708",
+	62: "850 WE/Latin 1",
+	63: "437 US",
+}
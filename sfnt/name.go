@@ -0,0 +1,78 @@
+package sfnt
+
+import (
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A string from the name table
+type NameRecord struct {
+	Platform uint16
+	Encoding uint16
+	Language uint16
+	ID       uint16
+	Value    string
+}
+
+func (f *Font) NameRecords() []NameRecord {
+	name := f.Table("name")
+	count := int(u16(name, 2))
+	storage := int(u16(name, 4))
+
+	ret := []NameRecord{}
+	for idx := range count {
+		rec := 6 + 12*idx
+		length, off := int(u16(name, rec+8)), storage+int(u16(name, rec+10))
+		if off+length > len(name) {
+			continue
+		}
+		raw := name[off : off+length]
+
+		record := NameRecord{
+			Platform: u16(name, rec),
+			Encoding: u16(name, rec+2),
+			Language: u16(name, rec+4),
+			ID:       u16(name, rec+6),
+		}
+		switch {
+		case record.Platform == 0 || record.Platform == 3:
+			units := make([]uint16, len(raw)/2)
+			for n := range units {
+				units[n] = u16(raw, 2*n)
+			}
+			record.Value = string(utf16.Decode(units))
+		case record.Platform == 1 && record.Encoding == 0:
+			text, _ := charmap.Macintosh.NewDecoder().Bytes(raw)
+			record.Value = string(text)
+		default:
+			continue
+		}
+		ret = append(ret, record)
+	}
+	return ret
+}
+
+// The English string for a name ID, or any language if there is none
+func (f *Font) Name(id uint16) string {
+	best, bestScore := "", 0
+	for _, rec := range f.NameRecords() {
+		if rec.ID != id {
+			continue
+		}
+
+		score := 1
+		switch {
+		case rec.Platform == 3 && rec.Language == 0x409:
+			score = 4
+		case rec.Platform == 1 && rec.Language == 0:
+			score = 3
+		case rec.Platform == 0 || rec.Platform == 3:
+			score = 2
+		}
+		if score > bestScore {
+			best, bestScore = rec.Value, score
+		}
+	}
+	return best
+}
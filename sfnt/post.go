@@ -0,0 +1,111 @@
+package sfnt
+
+// Glyph names indexed by glyph ID, from the post table or the CFF charset.
+// Empty strings where the font doesn't name a glyph.
+func (f *Font) GlyphNames() []string {
+	f.mut.Lock()
+	cached := f.names
+	f.mut.Unlock()
+	if cached != nil {
+		return cached
+	}
+
+	num := f.NumGlyphs()
+	ret := postNames(f.Table("post"), num)
+	if ret == nil {
+		ret = cffNames(f.Table("CFF "), num)
+	}
+	if ret == nil {
+		ret = make([]string, num)
+	}
+
+	f.mut.Lock()
+	f.names = ret
+	f.mut.Unlock()
+	return ret
+}
+
+func postNames(post []byte, num int) []string {
+	switch u32(post, 0) {
+	case 0x00010000:
+		ret := make([]string, num)
+		copy(ret, macNames[:])
+		return ret
+
+	case 0x00020000:
+		count := int(u16(post, 32))
+		index := 34
+		strings := index + 2*count
+		if len(post) < strings {
+			return nil
+		}
+
+		extra := []string{}
+		for off := strings; off < len(post); {
+			size := int(post[off])
+			if off+1+size > len(post) {
+				break
+			}
+			extra = append(extra, string(post[off+1:off+1+size]))
+			off += 1 + size
+		}
+
+		ret := make([]string, num)
+		for gid := range min(count, num) {
+			idx := int(u16(post, index+2*gid))
+			switch {
+			case idx < len(macNames):
+				ret[gid] = macNames[idx]
+			case idx-len(macNames) < len(extra):
+				ret[gid] = extra[idx-len(macNames)]
+			}
+		}
+		return ret
+	}
+
+	// Version 3 carries no names at all
+	return nil
+}
+
+// Standard Macintosh glyph order used by post versions 1 and 2
+var macNames = [...]string{
+	".notdef", ".null", "nonmarkingreturn", "space", "exclam", "quotedbl",
+	"numbersign", "dollar", "percent", "ampersand", "quotesingle",
+	"parenleft", "parenright", "asterisk", "plus", "comma", "hyphen",
+	"period", "slash", "zero", "one", "two", "three", "four", "five", "six",
+	"seven", "eight", "nine", "colon", "semicolon", "less", "equal",
+	"greater", "question", "at", "A", "B", "C", "D", "E", "F", "G", "H", "I",
+	"J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X",
+	"Y", "Z", "bracketleft", "backslash", "bracketright", "asciicircum",
+	"underscore", "grave", "a", "b", "c", "d", "e", "f", "g", "h", "i", "j",
+	"k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u", "v", "w", "x", "y",
+	"z", "braceleft", "bar", "braceright", "asciitilde", "Adieresis",
+	"Aring", "Ccedilla", "Eacute", "Ntilde", "Odieresis", "Udieresis",
+	"aacute", "agrave", "acircumflex", "adieresis", "atilde", "aring",
+	"ccedilla", "eacute", "egrave", "ecircumflex", "edieresis", "iacute",
+	"igrave", "icircumflex", "idieresis", "ntilde", "oacute", "ograve",
+	"ocircumflex", "odieresis", "otilde", "uacute", "ugrave", "ucircumflex",
+	"udieresis", "dagger", "degree", "cent", "sterling", "section",
+	"bullet", "paragraph", "germandbls", "registered", "copyright",
+	"trademark", "acute", "dieresis", "notequal", "AE", "Oslash",
+	"infinity", "plusminus", "lessequal", "greaterequal", "yen", "mu",
+	"partialdiff", "summation", "product", "pi", "integral", "ordfeminine",
+	"ordmasculine", "Omega", "ae", "oslash", "questiondown", "exclamdown",
+	"logicalnot", "radical", "florin", "approxequal", "Delta",
+	"guillemotleft", "guillemotright", "ellipsis", "nonbreakingspace",
+	"Agrave", "Atilde", "Otilde", "OE", "oe", "endash", "emdash",
+	"quotedblleft", "quotedblright", "quoteleft", "quoteright", "divide",
+	"lozenge", "ydieresis", "Ydieresis", "fraction", "currency",
+	"guilsinglleft", "guilsinglright", "fi", "fl", "daggerdbl",
+	"periodcentered", "quotesinglbase", "quotedblbase", "perthousand",
+	"Acircumflex", "Ecircumflex", "Aacute", "Edieresis", "Egrave",
+	"Iacute", "Icircumflex", "Idieresis", "Igrave", "Oacute",
+	"Ocircumflex", "apple", "Ograve", "Uacute", "Ucircumflex", "Ugrave",
+	"dotlessi", "circumflex", "tilde", "macron", "breve", "dotaccent",
+	"ring", "cedilla", "hungarumlaut", "ogonek", "caron", "Lslash",
+	"lslash", "Scaron", "scaron", "Zcaron", "zcaron", "brokenbar", "Eth",
+	"eth", "Yacute", "yacute", "Thorn", "thorn", "minus", "multiply",
+	"onesuperior", "twosuperior", "threesuperior", "onehalf", "onequarter",
+	"threequarters", "franc", "Gbreve", "gbreve", "Idotaccent", "Scedilla",
+	"scedilla", "Cacute", "cacute", "Ccaron", "ccaron", "dcroat",
+}
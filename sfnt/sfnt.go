@@ -0,0 +1,103 @@
+package sfnt
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrMissing   = errors.New("sfnt: table not present")
+	ErrTruncated = errors.New("sfnt: table is truncated")
+	ErrFormat    = errors.New("sfnt: unsupported table format")
+)
+
+// Lazily parsed view of an OpenType font. Tables are fetched through the
+// callback, eg QRawFont.FontTable, so the font file itself never has to be
+// located on disk.
+type Font struct {
+	table func(tag string) []byte
+	tags  []string
+
+	mut    sync.Mutex
+	tables map[string][]byte
+	cmap   map[rune]uint16
+	names  []string
+	kern   *kerning
+}
+
+func New(table func(tag string) []byte) *Font {
+	return &Font{
+		table:  table,
+		tables: map[string][]byte{},
+	}
+}
+
+// Unlocked while reading, `table` may wait on another thread that uses the
+// font too
+func (f *Font) Table(tag string) []byte {
+	f.mut.Lock()
+	data, ok := f.tables[tag]
+	f.mut.Unlock()
+	if ok {
+		return data
+	}
+
+	data = f.table(tag)
+	f.mut.Lock()
+	f.tables[tag] = data
+	f.mut.Unlock()
+	return data
+}
+
+func (f *Font) HasTable(tag string) bool {
+	return len(f.Table(tag)) > 0
+}
+
+func (f *Font) NumGlyphs() int {
+	maxp := f.Table("maxp")
+	return int(u16(maxp, 4))
+}
+
+func u8(b []byte, off int) uint8 {
+	if off < 0 || off >= len(b) {
+		return 0
+	}
+	return b[off]
+}
+
+func u16(b []byte, off int) uint16 {
+	if off < 0 || off+2 > len(b) {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b[off:])
+}
+
+func i16(b []byte, off int) int16 {
+	return int16(u16(b, off))
+}
+
+func u32(b []byte, off int) uint32 {
+	if off < 0 || off+4 > len(b) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b[off:])
+}
+
+func i32(b []byte, off int) int32 {
+	return int32(u32(b, off))
+}
+
+func tag(b []byte, off int) string {
+	if off < 0 || off+4 > len(b) {
+		return ""
+	}
+	return string(b[off : off+4])
+}
+
+func sub(b []byte, off int) []byte {
+	if off < 0 || off > len(b) {
+		return nil
+	}
+	return b[off:]
+}
@@ -0,0 +1,407 @@
+package sfnt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Converts WOFF and WOFF2 files to a plain sfnt, anything else is returned
+// as is
+func Unwrap(data []byte) ([]byte, error) {
+	switch tag(data, 0) {
+	case "wOFF":
+		return decodeWOFF(data)
+	case "wOF2":
+		return decodeWOFF2(data)
+	}
+	return data, nil
+}
+
+func decodeWOFF(data []byte) ([]byte, error) {
+	num := int(u16(data, 12))
+	if 44+20*num > len(data) {
+		return nil, ErrTruncated
+	}
+
+	tables := map[string][]byte{}
+	for idx := range num {
+		rec := 44 + 20*idx
+		off, compLength, origLength := int(u32(data, rec+4)), int(u32(data, rec+8)), int(u32(data, rec+12))
+		if off+compLength > len(data) {
+			return nil, ErrTruncated
+		}
+		table := data[off : off+compLength]
+		if compLength < origLength {
+			r, err := zlib.NewReader(bytes.NewReader(table))
+			if err != nil {
+				return nil, fmt.Errorf("woff: %s: %w", tag(data, rec), err)
+			}
+			table, err = io.ReadAll(io.LimitReader(r, int64(origLength)))
+			if err != nil {
+				return nil, fmt.Errorf("woff: %s: %w", tag(data, rec), err)
+			}
+		}
+		tables[tag(data, rec)] = table
+	}
+	return Assemble(tables), nil
+}
+
+// Tags WOFF2 refers to by index instead of spelling them out
+var woff2Tags = []string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post",
+	"cvt ", "fpgm", "glyf", "loca", "prep", "CFF ", "VORG", "EBDT",
+	"EBLC", "gasp", "hdmx", "kern", "LTSH", "PCLT", "VDMX", "vhea",
+	"vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC", "JSTF", "MATH",
+	"CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar",
+	"gvar", "hsty", "just", "lcar", "mort", "morx", "opbd", "prop",
+	"trak", "Zapf", "Silf", "Glat", "Gloc", "Feat", "Sill",
+}
+
+// Sequential reader for the variable length numbers of WOFF2
+type woff2Reader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *woff2Reader) byte() int {
+	if r.off >= len(r.b) {
+		r.err = ErrTruncated
+		return 0
+	}
+	r.off++
+	return int(r.b[r.off-1])
+}
+
+func (r *woff2Reader) u16() int {
+	return r.byte()<<8 | r.byte()
+}
+
+func (r *woff2Reader) bytes(n int) []byte {
+	if n < 0 || r.off+n > len(r.b) {
+		r.err = ErrTruncated
+		return nil
+	}
+	r.off += n
+	return r.b[r.off-n : r.off]
+}
+
+func (r *woff2Reader) base128() int {
+	ret := 0
+	for range 5 {
+		b := r.byte()
+		ret = ret<<7 | b&0x7F
+		if b&0x80 == 0 {
+			return ret
+		}
+	}
+	r.err = ErrFormat
+	return 0
+}
+
+func (r *woff2Reader) u255() int {
+	switch code := r.byte(); code {
+	case 253:
+		return r.u16()
+	case 255:
+		return r.byte() + 253
+	case 254:
+		return r.byte() + 506
+	default:
+		return code
+	}
+}
+
+type woff2Table struct {
+	tag               string
+	transformed       bool
+	origLength, start int
+	length            int
+}
+
+// Collections in WOFF2 aren't supported, they're rare on the web
+func decodeWOFF2(data []byte) ([]byte, error) {
+	if tag(data, 4) == "ttcf" {
+		return nil, fmt.Errorf("woff2: collections are not supported: %w", ErrFormat)
+	}
+	num := int(u16(data, 12))
+	compressed := int(u32(data, 20))
+
+	dir := &woff2Reader{b: data, off: 48}
+	entries := []woff2Table{}
+	start := 0
+	for range num {
+		flags := dir.byte()
+		t := woff2Table{}
+		if flags&0x3F == 0x3F {
+			t.tag = string(dir.bytes(4))
+		} else if int(flags&0x3F) < len(woff2Tags) {
+			t.tag = woff2Tags[flags&0x3F]
+		}
+		t.origLength = dir.base128()
+		t.length = t.origLength
+
+		// glyf and loca are transformed at version 0, everything else at 1
+		version := flags >> 6
+		if t.tag == "glyf" || t.tag == "loca" {
+			t.transformed = version == 0
+		} else {
+			t.transformed = version != 0
+		}
+		if t.transformed {
+			t.length = dir.base128()
+		}
+		t.start = start
+		start += t.length
+		entries = append(entries, t)
+	}
+	if dir.err != nil {
+		return nil, fmt.Errorf("woff2: table directory: %w", dir.err)
+	}
+	if dir.off+compressed > len(data) {
+		return nil, ErrTruncated
+	}
+
+	stream, err := io.ReadAll(io.LimitReader(
+		brotli.NewReader(bytes.NewReader(data[dir.off:dir.off+compressed])), int64(start),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("woff2: %w", err)
+	}
+	if len(stream) < start {
+		return nil, ErrTruncated
+	}
+
+	tables := map[string][]byte{}
+	var hmtx *woff2Table
+	for idx, t := range entries {
+		table := stream[t.start : t.start+t.length]
+		switch {
+		case t.tag == "glyf" && t.transformed:
+			glyf, loca, err := woff2Glyf(table)
+			if err != nil {
+				return nil, fmt.Errorf("woff2: glyf: %w", err)
+			}
+			tables["glyf"], tables["loca"] = glyf, loca
+		case t.tag == "loca" && t.transformed:
+			// Rebuilt along with glyf
+		case t.tag == "hmtx" && t.transformed:
+			hmtx = &entries[idx]
+		case t.transformed:
+			return nil, fmt.Errorf("woff2: unknown transform of %q: %w", t.tag, ErrFormat)
+		default:
+			tables[t.tag] = table
+		}
+	}
+
+	// Needs glyf for the side bearings it leaves out
+	if hmtx != nil {
+		table, err := woff2Hmtx(stream[hmtx.start:hmtx.start+hmtx.length], tables)
+		if err != nil {
+			return nil, fmt.Errorf("woff2: hmtx: %w", err)
+		}
+		tables["hmtx"] = table
+	}
+	return Assemble(tables), nil
+}
+
+// Decodes a coordinate pair of the glyph stream, relative to the previous
+// point
+func woff2Triplet(flag int, r *woff2Reader) (dx, dy int) {
+	sign := func(flag, value int) int {
+		if flag&1 != 0 {
+			return value
+		}
+		return -value
+	}
+	switch {
+	case flag < 10:
+		dy = sign(flag, (flag&14)<<7+r.byte())
+	case flag < 20:
+		dx = sign(flag, ((flag-10)&14)<<7+r.byte())
+	case flag < 84:
+		b0, b1 := flag-20, r.byte()
+		dx = sign(flag, 1+b0&0x30+b1>>4)
+		dy = sign(flag>>1, 1+(b0&0x0C)<<2+b1&0x0F)
+	case flag < 120:
+		b0 := flag - 84
+		dx = sign(flag, 1+(b0/12)<<8+r.byte())
+		dy = sign(flag>>1, 1+((b0%12)>>2)<<8+r.byte())
+	case flag < 124:
+		b0, b1, b2 := r.byte(), r.byte(), r.byte()
+		dx = sign(flag, b0<<4+b1>>4)
+		dy = sign(flag>>1, (b1&0x0F)<<8+b2)
+	default:
+		dx = sign(flag, r.u16())
+		dy = sign(flag>>1, r.u16())
+	}
+	return dx, dy
+}
+
+// Rebuilds glyf and loca from the split streams of the transformed table
+func woff2Glyf(b []byte) (glyf, loca []byte, err error) {
+	if len(b) < 36 {
+		return nil, nil, ErrTruncated
+	}
+	options := u16(b, 2)
+	numGlyphs, indexFormat := int(u16(b, 4)), u16(b, 6)
+
+	streams := make([]*woff2Reader, 7)
+	off := 36
+	for idx := range streams {
+		length := int(u32(b, 8+4*idx))
+		if off+length > len(b) {
+			return nil, nil, ErrTruncated
+		}
+		streams[idx] = &woff2Reader{b: b[off : off+length]}
+		off += length
+	}
+	contours, points, flags, glyphs, composites, bboxes, instructions :=
+		streams[0], streams[1], streams[2], streams[3], streams[4], streams[5], streams[6]
+	bitmap := bboxes.bytes(4 * ((numGlyphs + 31) / 32))
+	var overlap []byte
+	if options&0x1 != 0 {
+		overlap = sub(b, off)
+	}
+
+	be := binary.BigEndian
+	offsets := []int{0}
+	for gid := range numGlyphs {
+		explicit := bitmap != nil && bitmap[gid/8]&(0x80>>(gid%8)) != 0
+		var entry []byte
+
+		switch n := int(int16(contours.u16())); {
+		case n < 0:
+			// Composite records are stored as in glyf
+			box := bboxes.bytes(8)
+			start := composites.off
+			hasInstructions := false
+			for {
+				flag := composites.u16()
+				composites.bytes(2)
+				size := 2
+				if flag&0x1 != 0 {
+					size = 4
+				}
+				switch {
+				case flag&0x8 != 0:
+					size += 2
+				case flag&0x40 != 0:
+					size += 4
+				case flag&0x80 != 0:
+					size += 8
+				}
+				composites.bytes(size)
+				hasInstructions = hasInstructions || flag&0x100 != 0
+				if flag&0x20 == 0 || composites.err != nil {
+					break
+				}
+			}
+			entry = be.AppendUint16(nil, 0xFFFF)
+			entry = append(entry, box...)
+			entry = append(entry, composites.b[start:composites.off]...)
+			if hasInstructions {
+				length := glyphs.u255()
+				entry = be.AppendUint16(entry, uint16(length))
+				entry = append(entry, instructions.bytes(length)...)
+			}
+			for len(entry)%4 != 0 {
+				entry = append(entry, 0)
+			}
+		case n > 0:
+			g := glyfGlyph{}
+			total := 0
+			for range n {
+				total += points.u255()
+				g.contours = append(g.contours, total-1)
+			}
+			g.points = make([]point, total)
+			g.flags = make([]byte, total)
+			x, y := 0, 0
+			for idx := range total {
+				flag := flags.byte()
+				dx, dy := woff2Triplet(flag&0x7F, glyphs)
+				x, y = x+dx, y+dy
+				g.points[idx] = point{float64(x), float64(y)}
+				if flag&0x80 == 0 {
+					g.flags[idx] = 0x1
+				}
+			}
+			if overlap != nil && int(u8(overlap, gid/8))&(0x80>>(gid%8)) != 0 {
+				g.flags[0] |= 0x40
+			}
+			g.instructions = instructions.bytes(glyphs.u255())
+			entry = g.encode()
+			if explicit {
+				copy(entry[2:10], bboxes.bytes(8))
+			}
+		}
+
+		for _, s := range streams {
+			if s.err != nil {
+				return nil, nil, fmt.Errorf("glyph %d: %w", gid, s.err)
+			}
+		}
+		glyf = append(glyf, entry...)
+		offsets = append(offsets, len(glyf))
+	}
+
+	for _, off := range offsets {
+		if indexFormat == 0 {
+			loca = be.AppendUint16(loca, uint16(off/2))
+		} else {
+			loca = be.AppendUint32(loca, uint32(off))
+		}
+	}
+	return glyf, loca, nil
+}
+
+// Restores the side bearings the transformed hmtx drops, which equal the
+// xMin of each glyph
+func woff2Hmtx(b []byte, tables map[string][]byte) ([]byte, error) {
+	glyf := New(func(tag string) []byte { return tables[tag] })
+	numGlyphs := glyf.NumGlyphs()
+	metrics := int(u16(tables["hhea"], 34))
+	if tables["glyf"] == nil || metrics == 0 || metrics > numGlyphs {
+		return nil, errors.New("needs glyf, hhea and maxp")
+	}
+
+	r := &woff2Reader{b: b}
+	flags := r.byte()
+	advances := make([]int, metrics)
+	for idx := range advances {
+		advances[idx] = r.u16()
+	}
+	lsbs := make([]int, numGlyphs)
+	for idx := range lsbs {
+		explicit := flags&0x1 == 0
+		if idx >= metrics {
+			explicit = flags&0x2 == 0
+		}
+		if explicit {
+			lsbs[idx] = int(int16(r.u16()))
+		} else {
+			lsbs[idx] = int(i16(glyf.glyphData(idx), 2))
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	be := binary.BigEndian
+	var ret []byte
+	for idx, lsb := range lsbs {
+		if idx < metrics {
+			ret = be.AppendUint16(ret, uint16(advances[idx]))
+		}
+		ret = be.AppendUint16(ret, uint16(int16(lsb)))
+	}
+	return ret, nil
+}
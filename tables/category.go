@@ -0,0 +1,70 @@
+package tables
+
+import (
+	"unicode"
+)
+
+// Two letter general category, eg "Lu" or "Mn". Code points outside of every
+// category table are unassigned, "Cn". LC is a group of three categories, not
+// one of its own.
+func GeneralCategory(r rune) string {
+	for cat, table := range unicode.Categories {
+		if len(cat) == 2 && cat != "LC" && unicode.Is(table, r) {
+			return cat
+		}
+	}
+	return "Cn"
+}
+
+var invisibleNames = map[rune]string{
+	0x00AD: "Soft hyphen",
+	0x034F: "Grapheme joiner",
+	0x061C: "Bidi control",
+	0x115F: "Hangul filler",
+	0x1160: "Hangul filler",
+	0x180E: "Mongolian vowel separator",
+	0x200B: "ZWSP",
+	0x200C: "ZWNJ",
+	0x200D: "ZWJ",
+	0x200E: "Bidi control",
+	0x200F: "Bidi control",
+	0x2060: "Word joiner",
+	0x2061: "Invisible operator",
+	0x2062: "Invisible operator",
+	0x2063: "Invisible operator",
+	0x2064: "Invisible operator",
+	0x3164: "Hangul filler",
+	0xFEFF: "BOM / ZWNBSP",
+	0xFFA0: "Hangul filler",
+}
+
+// Describes why a code point would not show up when rendered, or an empty
+// string if it is visible.
+func InvisibleKind(r rune) string {
+	if name, ok := invisibleNames[r]; ok {
+		return name
+	}
+
+	switch {
+	case r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+		return "Bidi control"
+	case r >= 0xFE00 && r <= 0xFE0F, r >= 0xE0100 && r <= 0xE01EF:
+		return "Variation selector"
+	case r >= 0x180B && r <= 0x180F:
+		return "Variation selector"
+	case r >= 0xE0000 && r <= 0xE007F:
+		return "Tag"
+	case r == ' ':
+		return ""
+	}
+
+	switch GeneralCategory(r) {
+	case "Cc":
+		return "Control"
+	case "Cf":
+		return "Format"
+	case "Zs", "Zl", "Zp":
+		return "Whitespace"
+	}
+	return ""
+}
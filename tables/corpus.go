@@ -0,0 +1,134 @@
+package tables
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+type Corpus struct {
+	Text     string
+	Points   []rune
+	Contexts map[rune]string
+	Counts   map[rune]int
+}
+
+// Reads the human readable text out of a file. HTML markup and PO file
+// syntax are stripped so that tag names and msgid keys do not count towards
+// the coverage; anything else is read as plain text.
+func ReadCorpus(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm", ".xhtml":
+		return htmlText(data)
+	case ".po", ".pot":
+		return poText(string(data)), nil
+	}
+	return string(data), nil
+}
+
+func htmlText(data []byte) (string, error) {
+	tree, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	for node := range tree.Descendants() {
+		if node.Type != html.TextNode {
+			continue
+		}
+		if node.Parent != nil && (node.Parent.Data == "script" || node.Parent.Data == "style") {
+			continue
+		}
+		sb.WriteString(node.Data)
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// Only the translations matter, msgid is the source language
+func poText(data string) string {
+	sb := strings.Builder{}
+	inStr := false
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "msgstr"):
+			inStr = true
+			// The string starts at the first quote, there may be no space
+			// before it, or a plural index as in msgstr[1]
+			if idx := strings.Index(line, "\""); idx >= 0 {
+				line = line[idx:]
+			}
+		case strings.HasPrefix(line, "\"") && inStr:
+		default:
+			inStr = false
+			continue
+		}
+
+		unquoted, err := strconv.Unquote(line)
+		if err != nil {
+			continue
+		}
+		sb.WriteString(unquoted)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func NewCorpus(text string) Corpus {
+	ret := Corpus{
+		Text:     text,
+		Points:   []rune{},
+		Contexts: map[rune]string{},
+		Counts:   map[rune]int{},
+	}
+
+	runes := []rune(text)
+	for idx, r := range runes {
+		if unicode.IsControl(r) {
+			continue
+		}
+
+		ret.Counts[r]++
+		if _, ok := ret.Contexts[r]; ok {
+			continue
+		}
+
+		ret.Points = append(ret.Points, r)
+		start, end := max(idx-16, 0), min(idx+16, len(runes))
+		context := string(runes[start:end])
+		context = strings.Join(strings.Fields(context), " ")
+		ret.Contexts[r] = context
+	}
+
+	slices.Sort(ret.Points)
+	return ret
+}
+
+func ScriptOf(r rune) string {
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+
+	if unicode.Is(unicode.Inherited, r) {
+		return "Inherited"
+	}
+	if unicode.Is(unicode.Common, r) {
+		return "Common"
+	}
+	return "Unknown"
+}
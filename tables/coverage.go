@@ -0,0 +1,83 @@
+package tables
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Coverage struct {
+	Block     string `json:"block"`
+	Start     rune   `json:"start"`
+	End       rune   `json:"end"`
+	Assigned  int    `json:"assigned"`
+	Supported int    `json:"supported"`
+}
+
+func (c Coverage) Percent() float64 {
+	if c.Assigned == 0 {
+		return 0
+	}
+	return 100 * float64(c.Supported) / float64(c.Assigned)
+}
+
+func (c Coverage) Range() string {
+	return fmt.Sprintf("U+%04X..U+%04X", c.Start, c.End)
+}
+
+func WriteCoverageCSV(w io.Writer, font string, rows []Coverage) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"font", "block", "start", "end", "assigned", "supported", "percent"})
+	for _, row := range rows {
+		writer.Write([]string{
+			font,
+			row.Block,
+			fmt.Sprintf("%04X", row.Start),
+			fmt.Sprintf("%04X", row.End),
+			fmt.Sprint(row.Assigned),
+			fmt.Sprint(row.Supported),
+			fmt.Sprintf("%.2f", row.Percent()),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteCoverageJSON(w io.Writer, font string, rows []Coverage) error {
+	type jsonRow struct {
+		Coverage
+		Percent float64 `json:"percent"`
+	}
+
+	out := struct {
+		Font   string    `json:"font"`
+		Blocks []jsonRow `json:"blocks"`
+	}{font, []jsonRow{}}
+	for _, row := range rows {
+		out.Blocks = append(out.Blocks, jsonRow{row, row.Percent()})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(out)
+}
+
+func WriteCoverageMarkdown(w io.Writer, font string, rows []Coverage) error {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("# Coverage: %s\n\n", font))
+	sb.WriteString("| Block | Range | Supported | Assigned | Coverage |\n")
+	sb.WriteString("|---|---|--:|--:|---|\n")
+	for _, row := range rows {
+		bar := int(row.Percent() / 10)
+		sb.WriteString(fmt.Sprintf(
+			"| %s | %s | %d | %d | `%s%s` %.1f%% |\n",
+			row.Block, row.Range(), row.Supported, row.Assigned,
+			strings.Repeat("█", bar), strings.Repeat("░", 10-bar), row.Percent(),
+		))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
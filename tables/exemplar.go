@@ -0,0 +1,106 @@
+package tables
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+type Language struct {
+	Code string
+	Name string
+	// Code points of the CLDR exemplar characters, the ones everyday text in
+	// the language needs, with uppercase forms added for the scripts that
+	// capitalize. Sequences are split up, format controls such as ZWNJ left
+	// out.
+	Exemplars []rune
+}
+
+var (
+	_languages   []Language
+	languagesMut sync.Mutex
+)
+
+func ParseExemplars() []Language {
+	languagesMut.Lock()
+	defer languagesMut.Unlock()
+	if _languages != nil {
+		return _languages
+	}
+
+	for _, line := range readLines("data/Exemplars.txt") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		code, chars, ok := strings.Cut(line, ";")
+		if !ok {
+			continue
+		}
+
+		lang := Language{Code: strings.TrimSpace(code), Name: languageName(strings.TrimSpace(code))}
+		for _, item := range strings.Fields(chars) {
+			if strings.IndexFunc(item, casedScript) >= 0 {
+				item += strings.ToUpper(item)
+			}
+			for _, r := range item {
+				if !unicode.Is(unicode.Cf, r) && !slices.Contains(lang.Exemplars, r) {
+					lang.Exemplars = append(lang.Exemplars, r)
+				}
+			}
+		}
+		slices.Sort(lang.Exemplars)
+		_languages = append(_languages, lang)
+	}
+
+	slices.SortFunc(_languages, func(a, b Language) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return _languages
+}
+
+// Scripts whose everyday text has capitals. Georgian has Mtavruli, but
+// only for all caps titles.
+func casedScript(r rune) bool {
+	return unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic, unicode.Armenian)
+}
+
+// English name of a locale, eg "Serbian (Latin)". Tags().Name would call
+// that one Serbo-Croatian.
+func languageName(code string) string {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return code
+	}
+	base, script, region := tag.Raw()
+	name := display.English.Languages().Name(base)
+	if name == "" {
+		return code
+	}
+
+	extra := []string{}
+	if strings.Contains(code, "-"+script.String()) {
+		extra = append(extra, display.English.Scripts().Name(script))
+	}
+	if strings.Contains(code, "-"+region.String()) {
+		extra = append(extra, display.English.Regions().Name(region))
+	}
+	if len(extra) > 0 {
+		name += " (" + strings.Join(extra, ", ") + ")"
+	}
+	return name
+}
+
+// The exemplars `supports` is false for
+func (l Language) Missing(supports func(rune) bool) []rune {
+	ret := []rune{}
+	for _, r := range l.Exemplars {
+		if !supports(r) {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
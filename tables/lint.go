@@ -0,0 +1,111 @@
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"unicode"
+)
+
+// What the lint needs to know about the glyph a code point maps to
+type LintGlyph struct {
+	Point rune
+	Glyph uint16
+	// No contours. Color stays false unless COLR layers, SVG or bitmaps
+	// draw the glyph instead.
+	Empty    bool
+	Color    bool
+	Advance  int
+	Assigned bool
+}
+
+type LintIssue struct {
+	Point    rune   `json:"code_point"`
+	Glyph    uint16 `json:"glyph"`
+	Check    string `json:"check"`
+	Category string `json:"category"`
+	Detail   string `json:"detail"`
+}
+
+// The checks in the order they are reported, with what each flags
+var LintChecks = []struct {
+	ID, Title string
+}{
+	{"notdef", "Mapped to .notdef"},
+	{"empty", "Visible characters without ink"},
+	{"whitespace-ink", "Whitespace with ink"},
+	{"mark-advance", "Combining marks that advance"},
+	{"private-use", "Private use code points"},
+	{"unassigned", "Unassigned code points"},
+}
+
+// Flags cmap entries whose glyph can't be right for the character, sorted
+// by code point. A code point can fail several checks.
+func Lint(glyphs []LintGlyph) []LintIssue {
+	ret := []LintIssue{}
+	for _, g := range glyphs {
+		cat := GeneralCategory(g.Point)
+		add := func(check, detail string) {
+			ret = append(ret, LintIssue{g.Point, g.Glyph, check, cat, detail})
+		}
+
+		// The Ogham space mark is a Zs with a visible glyph by design
+		whitespace := (unicode.IsSpace(g.Point) || cat == "Zs" || cat == "Zl" || cat == "Zp") && g.Point != '\u1680'
+		switch {
+		case g.Glyph == 0:
+			add("notdef", "Draws the missing glyph box")
+		case whitespace && !g.Empty:
+			add("whitespace-ink", fmt.Sprintf("%s has contours", cat))
+		case g.Empty && !g.Color && lintVisible(g.Point, cat):
+			add("empty", fmt.Sprintf("%s mapped to a glyph without contours", cat))
+		}
+		if (cat == "Mn" || cat == "Me") && g.Glyph != 0 && g.Advance != 0 {
+			add("mark-advance", fmt.Sprintf("%s advances by %d units", cat, g.Advance))
+		}
+		switch {
+		case cat == "Co":
+			add("private-use", "Meaning is up to the font, other fonts differ")
+		case !g.Assigned:
+			add("unassigned", "Not a character in the Unicode data")
+		}
+	}
+
+	slices.SortStableFunc(ret, func(a, b LintIssue) int {
+		return int(a.Point - b.Point)
+	})
+	return ret
+}
+
+// Letters, marks, numbers, punctuation and symbols that aren't invisible by
+// design, eg Hangul fillers, and the Ogham space mark
+func lintVisible(r rune, cat string) bool {
+	if r == '\u1680' {
+		return true
+	}
+	switch cat[0] {
+	case 'L', 'M', 'N', 'P', 'S':
+		return InvisibleKind(r) == ""
+	}
+	return false
+}
+
+func WriteLintJSON(w io.Writer, font string, issues []LintIssue) error {
+	counts := map[string]int{}
+	for _, check := range LintChecks {
+		counts[check.ID] = 0
+	}
+	for _, issue := range issues {
+		counts[issue.Check]++
+	}
+
+	out := struct {
+		Font   string         `json:"font"`
+		Counts map[string]int `json:"counts"`
+		Issues []LintIssue    `json:"issues"`
+	}{font, counts, issues}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(out)
+}
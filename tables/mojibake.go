@@ -0,0 +1,172 @@
+package tables
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var TextEncodings = map[string]encoding.Encoding{
+	"UTF-8":        unicode.UTF8,
+	"UTF-16LE":     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	"UTF-16BE":     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	"ISO-8859-1":   charmap.ISO8859_1,
+	"ISO-8859-2":   charmap.ISO8859_2,
+	"ISO-8859-15":  charmap.ISO8859_15,
+	"Windows-1250": charmap.Windows1250,
+	"Windows-1251": charmap.Windows1251,
+	"Windows-1252": charmap.Windows1252,
+	"KOI8-R":       charmap.KOI8R,
+	"Mac Roman":    charmap.Macintosh,
+	"CP437":        charmap.CodePage437,
+	"CP850":        charmap.CodePage850,
+	"Shift_JIS":    japanese.ShiftJIS,
+	"EUC-JP":       japanese.EUCJP,
+	"GBK":          simplifiedchinese.GBK,
+	"Big5":         traditionalchinese.Big5,
+	"EUC-KR":       korean.EUCKR,
+}
+
+// Encodings that text is commonly misread as. UTF-16 is left out since
+// nearly any byte string is "valid" UTF-16, so it would drown out the
+// sensible repairs.
+var misreadEncodings = []string{
+	"Windows-1252",
+	"ISO-8859-1",
+	"ISO-8859-15",
+	"Windows-1250",
+	"Windows-1251",
+	"ISO-8859-2",
+	"KOI8-R",
+	"Mac Roman",
+	"CP437",
+	"CP850",
+	"Shift_JIS",
+	"EUC-JP",
+	"GBK",
+	"Big5",
+	"EUC-KR",
+}
+
+type Decoding struct {
+	Encoding string
+	Text     string
+	Invalid  int
+}
+
+type Repair struct {
+	// Encodings the text was wrongly decoded as, outermost first
+	Steps []string
+	Text  string
+	Score int
+}
+
+func EncodingNames() []string {
+	keys := []string{}
+	for key := range TextEncodings {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func DecodeAll(data []byte) []Decoding {
+	ret := []Decoding{}
+	for _, name := range EncodingNames() {
+		text, err := TextEncodings[name].NewDecoder().Bytes(data)
+		if err != nil {
+			continue
+		}
+
+		ret = append(ret, Decoding{
+			Encoding: name,
+			Text:     string(text),
+			Invalid:  strings.Count(string(text), "�"),
+		})
+	}
+	return ret
+}
+
+// Reverses a wrong decode by re-encoding the text as `name`. Windows-1252
+// decoders in the wild pass the five undefined bytes through as C1
+// controls, so those are accepted here too.
+func unread(text, name string) ([]byte, bool) {
+	enc := TextEncodings[name].NewEncoder()
+	ret := []byte{}
+	for _, r := range text {
+		b, err := enc.Bytes([]byte(string(r)))
+		if err == nil {
+			ret = append(ret, b...)
+			enc.Reset()
+			continue
+		}
+
+		if name == "Windows-1252" && (r == 0x81 || r == 0x8D || r == 0x8F || r == 0x90 || r == 0x9D) {
+			ret = append(ret, byte(r))
+			enc.Reset()
+			continue
+		}
+		return nil, false
+	}
+	return ret, true
+}
+
+func multibyteRunes(text string) int {
+	n := 0
+	for _, r := range text {
+		if r >= 0x80 {
+			n++
+		}
+	}
+	return n
+}
+
+func RepairMojibake(text string) []Repair {
+	ret := []Repair{}
+	seen := map[string]bool{text: true}
+
+	var walk func(cur string, steps []string)
+	walk = func(cur string, steps []string) {
+		if len(steps) >= 3 {
+			return
+		}
+
+		for _, name := range misreadEncodings {
+			data, ok := unread(cur, name)
+			if !ok || !utf8.Valid(data) {
+				continue
+			}
+
+			fixed := string(data)
+			if seen[fixed] || multibyteRunes(fixed) == 0 {
+				continue
+			}
+			seen[fixed] = true
+
+			next := append(slices.Clone(steps), name)
+			ret = append(ret, Repair{
+				Steps: next,
+				Text:  fixed,
+				Score: utf8.RuneCountInString(text) - utf8.RuneCountInString(fixed),
+			})
+			walk(fixed, next)
+		}
+	}
+	walk(text, []string{})
+
+	slices.SortStableFunc(ret, func(a, b Repair) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		return len(a.Steps) - len(b.Steps)
+	})
+	return ret
+}
@@ -25,6 +25,7 @@ type Node struct {
 	Refs     []string
 	Approx   []string
 	Equiv    []string
+	Subhead  string
 	Block
 	Raw string
 }
@@ -213,12 +214,25 @@ func ParseNamesList() map[string]*Node {
 	}
 
 	var lastNode *Node
+	subhead := ""
 
 	for _, line := range lines {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
+		if strings.HasPrefix(line, "@@\t") {
+			// Block header
+			subhead = ""
+			continue
+		}
+
+		if strings.HasPrefix(line, "@\t") {
+			// Subheader, eg "ASCII digits"
+			subhead = strings.TrimSpace(line[1:])
+			continue
+		}
+
 		if strings.HasPrefix(line, "@") {
 			// Comment line
 			continue
@@ -226,6 +240,7 @@ func ParseNamesList() map[string]*Node {
 
 		if !strings.HasPrefix(line, "\t") {
 			lastNode = newNode(blocks, line)
+			lastNode.Subhead = subhead
 			lastNode.Raw += line + "\n"
 			names[lastNode.Code] = lastNode
 			continue